@@ -0,0 +1,117 @@
+package main
+
+import "math/bits"
+
+// precomputed attack sets of the leaper pieces, bit i corresponds to Position(i)
+var (
+	knightAttacks [BoardSize * BoardSize]uint64
+	kingAttacks   [BoardSize * BoardSize]uint64
+	pawnAttacks   [2][BoardSize * BoardSize]uint64 // [is_white] squares attacked by a pawn standing on the square
+)
+
+var rookDirections = [...]MoveStencil{{0, 1}, {1, 0}, {0, -1}, {-1, 0}}
+var bishopDirections = [...]MoveStencil{{1, 1}, {-1, 1}, {1, -1}, {-1, -1}}
+
+func stencilsAttackSet(pos Position, stencils []MoveStencil) uint64 {
+	var res uint64
+	for _, st := range stencils {
+		r, c := applyStencil(pos, st)
+		if CheckBoardPos(r, c) {
+			res |= 1 << MakePos(r, c)
+		}
+	}
+	return res
+}
+
+func init() {
+	for i := range knightAttacks {
+		pos := Position(i)
+		knightAttacks[i] = stencilsAttackSet(pos, KnightStencils[:])
+		kingAttacks[i] = stencilsAttackSet(pos, KingStencils[:])
+		pawnAttacks[0][i] = stencilsAttackSet(pos, []MoveStencil{{-1, -1}, {-1, 1}})
+		pawnAttacks[1][i] = stencilsAttackSet(pos, []MoveStencil{{1, -1}, {1, 1}})
+	}
+}
+
+func boolIndex(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// squares of pieces from set which are equal to piece
+func leaperAttackers(set uint64, board *Board, piece Piece, first_only bool) uint64 {
+	var res uint64
+	for set != 0 {
+		i := bits.TrailingZeros64(set)
+		set &= set - 1
+		if board[i] == piece {
+			res |= 1 << i
+			if first_only {
+				return res
+			}
+		}
+	}
+	return res
+}
+
+// first pieces along the directions from pos which are equal to one of the pieces
+func sliderAttackers(pos Position, board *Board, directions []MoveStencil, piece_a, piece_b Piece, first_only bool) uint64 {
+	var res uint64
+	for _, dir := range directions {
+		r, c := applyStencil(pos, dir)
+		for CheckBoardPos(r, c) {
+			p := board.GetPiece(MakePos(r, c))
+			if p != NoPiece {
+				if p == piece_a || p == piece_b {
+					res |= 1 << MakePos(r, c)
+					if first_only {
+						return res
+					}
+				}
+				break
+			}
+			r += dir[0]
+			c += dir[1]
+		}
+	}
+	return res
+}
+
+func attackers(pos Position, board *Board, by_white bool, first_only bool) uint64 {
+	var color Piece = 0
+	if by_white {
+		color = White
+	}
+	var res uint64
+	// a pawn of color by_white attacks pos from the squares attacked by the opposite pawn on pos
+	res |= leaperAttackers(pawnAttacks[boolIndex(!by_white)][pos], board, Pawn|color, first_only)
+	if first_only && res != 0 {
+		return res
+	}
+	res |= leaperAttackers(knightAttacks[pos], board, Knight|color, first_only)
+	if first_only && res != 0 {
+		return res
+	}
+	res |= sliderAttackers(pos, board, bishopDirections[:], Bishop|color, Queen|color, first_only)
+	if first_only && res != 0 {
+		return res
+	}
+	res |= sliderAttackers(pos, board, rookDirections[:], Rook|color, Queen|color, first_only)
+	if first_only && res != 0 {
+		return res
+	}
+	res |= leaperAttackers(kingAttacks[pos], board, King|color, first_only)
+	return res
+}
+
+// return true if pos is attacked by any piece of the color by_white, the piece on pos is ignored
+func IsSquareAttacked(pos Position, board *Board, by_white bool) bool {
+	return attackers(pos, board, by_white, true) != 0
+}
+
+// squares of all pieces of the color by_white attacking pos, bit i corresponds to Position(i)
+func Attackers(pos Position, board *Board, by_white bool) uint64 {
+	return attackers(pos, board, by_white, false)
+}
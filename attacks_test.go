@@ -0,0 +1,57 @@
+package main
+
+import "testing"
+
+func squareSet(squares ...string) uint64 {
+	var res uint64
+	for _, sq := range squares {
+		pos, _ := MakePiecePosFromFEN(sq)
+		res |= 1 << pos
+	}
+	return res
+}
+
+func TestAttackers(t *testing.T) {
+	board, _ := makeTestPosition("4k3/8/2n2q2/1p6/3R4/8/4B3/1K1Q4 w - - 0 1", t)
+	cases := []struct {
+		square   string
+		by_white bool
+		expected uint64
+	}{
+		{"d4", false, squareSet("c6", "f6")},
+		{"c4", false, squareSet("b5")},
+		{"c4", true, squareSet("d4", "e2")}, // through the empty d3
+		{"d5", true, squareSet("d4")},       // d1 queen is behind the rook
+		{"d8", false, squareSet("c6", "e8", "f6")},
+		{"a2", true, squareSet("b1")},
+		{"h8", true, 0},
+	}
+	for _, c := range cases {
+		pos, _ := MakePiecePosFromFEN(c.square)
+		attackers := Attackers(pos, &board, c.by_white)
+		if attackers != c.expected {
+			t.Errorf("%s: attackers %x, expected %x", c.square, attackers, c.expected)
+		}
+		assert_equal(IsSquareAttacked(pos, &board, c.by_white), c.expected != 0, t)
+	}
+}
+
+// IsMovePossible should accept exactly the moves produced by the legal move generator
+func TestIsMovePossible(t *testing.T) {
+	for _, fen := range []string{perftInitial, perftKiwipete, perftPos3, perftPos4, perftPos5, perftPos6,
+		"r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 1", "4k3/8/8/2pP4/8/8/8/4K3 w - c6 0 1"} {
+		board, bs := makeTestPosition(fen, t)
+		legal := GenerateLegalMoves(&board, bs)
+		for start := Position(0); start < 64; start++ {
+			for end := Position(0); end < 64; end++ {
+				for _, promote := range [...]Piece{NoPiece, Knight, Queen} {
+					var move Move
+					move = move.SetStart(start).SetEnd(end).SetPromote(promote)
+					if IsMovePossible(move, &board, bs) != containsMove(legal, move) {
+						t.Errorf("%s: move %v disagrees with the legal move generator", fen, move)
+					}
+				}
+			}
+		}
+	}
+}
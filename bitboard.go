@@ -0,0 +1,154 @@
+package main
+
+import (
+	"math/bits"
+	"strings"
+)
+
+// set of squares, bit i corresponds to Position(i)
+type Bitboard uint64
+
+const (
+	FileA Bitboard = 0x0101010101010101
+	FileH Bitboard = FileA << 7
+	Rank1 Bitboard = 0xff
+	Rank8 Bitboard = Rank1 << 56
+)
+
+func SquareBB(pos Position) Bitboard {
+	return 1 << pos
+}
+
+func (b Bitboard) Has(pos Position) bool {
+	return b&SquareBB(pos) != 0
+}
+
+func (b Bitboard) Count() int {
+	return bits.OnesCount64(uint64(b))
+}
+
+// least significant square, b should not be empty
+func (b Bitboard) LSB() Position {
+	return Position(bits.TrailingZeros64(uint64(b)))
+}
+
+// removes and returns the least significant square, b should not be empty
+func (b *Bitboard) PopLSB() Position {
+	pos := b.LSB()
+	*b &= *b - 1
+	return pos
+}
+
+func (b Bitboard) North() Bitboard     { return b << 8 }
+func (b Bitboard) South() Bitboard     { return b >> 8 }
+func (b Bitboard) East() Bitboard      { return (b &^ FileH) << 1 }
+func (b Bitboard) West() Bitboard      { return (b &^ FileA) >> 1 }
+func (b Bitboard) NorthEast() Bitboard { return (b &^ FileH) << 9 }
+func (b Bitboard) NorthWest() Bitboard { return (b &^ FileA) << 7 }
+func (b Bitboard) SouthEast() Bitboard { return (b &^ FileH) >> 7 }
+func (b Bitboard) SouthWest() Bitboard { return (b &^ FileA) >> 9 }
+
+// board diagram with rank 8 at the top, x for the squares in the set
+func (b Bitboard) String() string {
+	var sb strings.Builder
+	for r := int(BoardSize) - 1; r >= 0; r-- {
+		for c := 0; c < int(BoardSize); c++ {
+			if b.Has(MakePos(r, c)) {
+				sb.WriteByte('x')
+			} else {
+				sb.WriteByte('.')
+			}
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// bitboard representation of the Board
+type BitBoards struct {
+	Pieces [King + 1]Bitboard // by piece type, index NoPiece is unused
+	Colors [2]Bitboard        // [0] black, [1] white
+}
+
+func MakeBitBoards(board *Board) BitBoards {
+	var res BitBoards
+	for i, piece := range board {
+		res.toggle(piece, Position(i))
+	}
+	return res
+}
+
+func (bb *BitBoards) Board() Board {
+	res := MakeEmptyBoard()
+	for t := Pawn; t <= King; t++ {
+		for _, is_white := range [2]bool{true, false} {
+			set := bb.PieceSet(MakePiece(t, is_white))
+			for set != 0 {
+				res.SetPiece(set.PopLSB(), MakePiece(t, is_white))
+			}
+		}
+	}
+	return res
+}
+
+func (bb *BitBoards) toggle(piece Piece, pos Position) {
+	if piece == NoPiece {
+		return
+	}
+	bb.Pieces[piece.GetType()] ^= SquareBB(pos)
+	bb.Colors[boolIndex(piece.IsWhite())] ^= SquareBB(pos)
+}
+
+func (bb *BitBoards) Occupied() Bitboard {
+	return bb.Colors[0] | bb.Colors[1]
+}
+
+func (bb *BitBoards) ColorSet(is_white bool) Bitboard {
+	return bb.Colors[boolIndex(is_white)]
+}
+
+// squares of the pieces equal to piece
+func (bb *BitBoards) PieceSet(piece Piece) Bitboard {
+	return bb.Pieces[piece.GetType()] & bb.Colors[boolIndex(piece.IsWhite())]
+}
+
+func (bb *BitBoards) GetPiece(pos Position) Piece {
+	if !bb.Occupied().Has(pos) {
+		return NoPiece
+	}
+	for t := Pawn; t <= King; t++ {
+		if bb.Pieces[t].Has(pos) {
+			return MakePiece(t, bb.Colors[1].Has(pos))
+		}
+	}
+	return NoPiece
+}
+
+func (bb *BitBoards) SetPiece(pos Position, piece Piece) {
+	bb.toggle(bb.GetPiece(pos), pos)
+	bb.toggle(piece, pos)
+}
+
+// applies changes of the board made by MakeMove or reverted by UnmakeMove,
+// piece is the moved piece before the move
+func (bb *BitBoards) ToggleMove(move Move, piece Piece, undo MoveUndo) {
+	start := move.GetStart()
+	end := move.GetEnd()
+	bb.toggle(piece, start)
+	if promote := move.GetPromote(); promote != NoPiece {
+		bb.toggle(promote|(piece&White), end)
+	} else {
+		bb.toggle(piece, end)
+	}
+	if undo.IsEnPassant {
+		bb.toggle(undo.Captured, MakePos(start.GetRow(), end.GetCol()))
+	} else {
+		bb.toggle(undo.Captured, end)
+	}
+	if undo.IsCastle {
+		rook_start, rook_end, _ := castleRookMove(start, end)
+		rook := Rook | (piece & White)
+		bb.toggle(rook, rook_start)
+		bb.toggle(rook, rook_end)
+	}
+}
@@ -0,0 +1,272 @@
+package main
+
+/*
+legal move generation on bitboards:
+checkers, pinned pieces and squares attacked by the opponent are computed once per position,
+so the moves are emitted without making them and checking the king afterwards
+*/
+
+var (
+	betweenBB [BoardSize * BoardSize][BoardSize * BoardSize]Bitboard // squares strictly between two aligned squares
+	lineBB    [BoardSize * BoardSize][BoardSize * BoardSize]Bitboard // whole line through two aligned squares
+)
+
+func init() {
+	for a := Position(0); a < 64; a++ {
+		for b := Position(0); b < 64; b++ {
+			if a == b {
+				continue
+			}
+			for _, directions := range [][]MoveStencil{rookDirections[:], bishopDirections[:]} {
+				if !slidingAttacks(a, 0, directions).Has(b) {
+					continue
+				}
+				betweenBB[a][b] = slidingAttacks(a, SquareBB(b), directions) & slidingAttacks(b, SquareBB(a), directions)
+				lineBB[a][b] = (slidingAttacks(a, 0, directions) & slidingAttacks(b, 0, directions)) | SquareBB(a) | SquareBB(b)
+			}
+		}
+	}
+}
+
+// pieces of the color by_white attacking pos with the given occupancy
+func attackersBB(bb *BitBoards, pos Position, occupied Bitboard, by_white bool) Bitboard {
+	color := bb.ColorSet(by_white)
+	queens := bb.Pieces[Queen]
+	return (Bitboard(pawnAttacks[boolIndex(!by_white)][pos]) & bb.Pieces[Pawn] & color) |
+		(Bitboard(knightAttacks[pos]) & bb.Pieces[Knight] & color) |
+		(Bitboard(kingAttacks[pos]) & bb.Pieces[King] & color) |
+		(BishopAttacks(pos, occupied) & (bb.Pieces[Bishop] | queens) & color) |
+		(RookAttacks(pos, occupied) & (bb.Pieces[Rook] | queens) & color)
+}
+
+// all squares attacked by the color by_white with the given occupancy
+func attackedSquaresBB(bb *BitBoards, occupied Bitboard, by_white bool) Bitboard {
+	color := bb.ColorSet(by_white)
+	pawns := bb.Pieces[Pawn] & color
+	var res Bitboard
+	if by_white {
+		res = pawns.NorthEast() | pawns.NorthWest()
+	} else {
+		res = pawns.SouthEast() | pawns.SouthWest()
+	}
+	for set := bb.Pieces[Knight] & color; set != 0; {
+		res |= Bitboard(knightAttacks[set.PopLSB()])
+	}
+	for set := (bb.Pieces[Bishop] | bb.Pieces[Queen]) & color; set != 0; {
+		res |= BishopAttacks(set.PopLSB(), occupied)
+	}
+	for set := (bb.Pieces[Rook] | bb.Pieces[Queen]) & color; set != 0; {
+		res |= RookAttacks(set.PopLSB(), occupied)
+	}
+	for set := bb.Pieces[King] & color; set != 0; {
+		res |= Bitboard(kingAttacks[set.PopLSB()])
+	}
+	return res
+}
+
+// own pieces which can not leave the line between the king and an enemy slider
+func pinnedBB(bb *BitBoards, king Position, is_white bool) Bitboard {
+	own := bb.ColorSet(is_white)
+	enemy := bb.ColorSet(!is_white)
+	queens := bb.Pieces[Queen]
+	snipers := (RookAttacks(king, enemy) & (bb.Pieces[Rook] | queens) & enemy) |
+		(BishopAttacks(king, enemy) & (bb.Pieces[Bishop] | queens) & enemy)
+	var res Bitboard
+	for snipers != 0 {
+		blockers := betweenBB[king][snipers.PopLSB()] & bb.Occupied()
+		if blockers.Count() == 1 && blockers&own != 0 {
+			res |= blockers
+		}
+	}
+	return res
+}
+
+// moves to the targets, the ones to the enemy squares are marked as captures
+func appendTargetMoves(moves []Move, start Position, targets Bitboard, enemy Bitboard) []Move {
+	var move Move
+	move = move.SetStart(start)
+	for set := targets & enemy; set != 0; {
+		moves = append(moves, move.SetEnd(set.PopLSB())|MoveFlagCapture)
+	}
+	for set := targets &^ enemy; set != 0; {
+		moves = append(moves, move.SetEnd(set.PopLSB()))
+	}
+	return moves
+}
+
+func appendPromotionMoves(moves []Move, move Move) []Move {
+	for _, p := range [...]Piece{Queen, Knight, Bishop, Rook} {
+		moves = append(moves, move.SetPromote(p))
+	}
+	return moves
+}
+
+// pawn moves to the targets, start of each move is offset squares back from its target
+func appendPawnTargetMoves(moves []Move, targets Bitboard, offset int, last_rank Bitboard, flags Move) []Move {
+	for targets != 0 {
+		end := targets.PopLSB()
+		var move Move
+		move = move.SetStart(Position(int(end)-offset)).SetEnd(end) | flags
+		if last_rank.Has(end) {
+			moves = appendPromotionMoves(moves, move)
+			continue
+		}
+		moves = append(moves, move)
+	}
+	return moves
+}
+
+func appendPawnMovesBB(moves []Move, bb *BitBoards, bs BoardState, pawns Bitboard, mask Bitboard) []Move {
+	is_white := bs.Get_Turn()
+	empty := ^bb.Occupied()
+	enemy := bb.ColorSet(!is_white)
+	var push, double_push, left, right Bitboard
+	var push_offset, left_offset, right_offset int
+	var last_rank Bitboard
+	if is_white {
+		push = pawns.North() & empty
+		double_push = (push & (Rank1 << 16)).North() & empty
+		left = pawns.NorthWest() & enemy
+		right = pawns.NorthEast() & enemy
+		push_offset, left_offset, right_offset = 8, 7, 9
+		last_rank = Rank8
+	} else {
+		push = pawns.South() & empty
+		double_push = (push & (Rank8 >> 16)).South() & empty
+		left = pawns.SouthWest() & enemy
+		right = pawns.SouthEast() & enemy
+		push_offset, left_offset, right_offset = -8, -9, -7
+		last_rank = Rank1
+	}
+	moves = appendPawnTargetMoves(moves, push&mask, push_offset, last_rank, 0)
+	moves = appendPawnTargetMoves(moves, double_push&mask, 2*push_offset, last_rank, MoveFlagDoublePush)
+	moves = appendPawnTargetMoves(moves, left&mask, left_offset, last_rank, MoveFlagCapture)
+	moves = appendPawnTargetMoves(moves, right&mask, right_offset, last_rank, MoveFlagCapture)
+	return moves
+}
+
+func appendEnPassantMovesBB(moves []Move, bb *BitBoards, bs BoardState, king Position, mask Bitboard) []Move {
+	if !bs.Get_IsEnPos() {
+		return moves
+	}
+	is_white := bs.Get_Turn()
+	en_pos := bs.Get_EnPos()
+	capture_pos := MakePos(en_pos.GetRow()-1, en_pos.GetCol())
+	if !is_white {
+		capture_pos = MakePos(en_pos.GetRow()+1, en_pos.GetCol())
+	}
+	if !bb.PieceSet(MakePiece(Pawn, !is_white)).Has(capture_pos) {
+		return moves
+	}
+	// the move should either capture the checker or block the check
+	if mask&(SquareBB(en_pos)|SquareBB(capture_pos)) == 0 {
+		return moves
+	}
+	enemy := bb.ColorSet(!is_white)
+	queens := bb.Pieces[Queen]
+	attackers := Bitboard(pawnAttacks[boolIndex(!is_white)][en_pos]) & bb.PieceSet(MakePiece(Pawn, is_white))
+	for attackers != 0 {
+		start := attackers.PopLSB()
+		// both pawns leave the rank at once, so the king is checked on the resulting occupancy
+		occupied := bb.Occupied() ^ SquareBB(start) ^ SquareBB(capture_pos) | SquareBB(en_pos)
+		if RookAttacks(king, occupied)&(bb.Pieces[Rook]|queens)&enemy != 0 ||
+			BishopAttacks(king, occupied)&(bb.Pieces[Bishop]|queens)&enemy != 0 {
+			continue
+		}
+		var move Move
+		moves = append(moves, move.SetStart(start).SetEnd(en_pos)|MoveFlagCapture|MoveFlagEnPassant)
+	}
+	return moves
+}
+
+func appendCastleMovesBB(moves []Move, bb *BitBoards, bs BoardState, danger Bitboard) []Move {
+	is_white := bs.Get_Turn()
+	var r int8 = 7
+	king_side, queen_side := bs.Get_k(), bs.Get_q()
+	if is_white {
+		r = 0
+		king_side, queen_side = bs.Get_K(), bs.Get_Q()
+	}
+	king := MakePos(r, 4)
+	if !bb.PieceSet(MakePiece(King, is_white)).Has(king) {
+		return moves
+	}
+	rooks := bb.PieceSet(MakePiece(Rook, is_white))
+	occupied := bb.Occupied()
+	var move Move
+	move = move.SetStart(king)
+	if king_side && rooks.Has(MakePos(r, 7)) &&
+		betweenBB[king][MakePos(r, 7)]&occupied == 0 &&
+		(SquareBB(MakePos(r, 5))|SquareBB(MakePos(r, 6)))&danger == 0 {
+		moves = append(moves, move.SetEnd(MakePos(r, 6))|MoveFlagKingCastle)
+	}
+	if queen_side && rooks.Has(MakePos(r, 0)) &&
+		betweenBB[king][MakePos(r, 0)]&occupied == 0 &&
+		(SquareBB(MakePos(r, 3))|SquareBB(MakePos(r, 2)))&danger == 0 {
+		moves = append(moves, move.SetEnd(MakePos(r, 2))|MoveFlagQueenCastle)
+	}
+	return moves
+}
+
+// appends all legal moves of the side to move, produces the same set of moves as AppendLegalMoves
+func AppendLegalMovesBB(moves []Move, gp *GamePosition) []Move {
+	bb := &gp.Bits
+	bs := gp.State
+	is_white := bs.Get_Turn()
+	own := bb.ColorSet(is_white)
+	kings := bb.PieceSet(MakePiece(King, is_white))
+	if kings == 0 {
+		return moves
+	}
+	king := kings.LSB()
+	occupied := bb.Occupied()
+
+	// sliders attack through the king, so it can not step back along the attack line
+	danger := attackedSquaresBB(bb, occupied&^kings, !is_white)
+	enemy := bb.ColorSet(!is_white)
+	moves = appendTargetMoves(moves, king, Bitboard(kingAttacks[king])&^own&^danger, enemy)
+
+	checkers := attackersBB(bb, king, occupied, !is_white)
+	if checkers.Count() > 1 {
+		return moves
+	}
+	mask := ^Bitboard(0) // squares which resolve the check
+	if checkers != 0 {
+		checker := checkers.LSB()
+		mask = checkers | betweenBB[king][checker]
+	}
+	pinned := pinnedBB(bb, king, is_white)
+
+	for set := own &^ kings; set != 0; {
+		start := set.PopLSB()
+		piece_mask := mask
+		if pinned.Has(start) {
+			piece_mask &= lineBB[king][start]
+		}
+		var targets Bitboard
+		switch {
+		case bb.Pieces[Pawn].Has(start):
+			moves = appendPawnMovesBB(moves, bb, bs, SquareBB(start), piece_mask)
+			continue
+		case bb.Pieces[Knight].Has(start):
+			targets = Bitboard(knightAttacks[start])
+		case bb.Pieces[Bishop].Has(start):
+			targets = BishopAttacks(start, occupied)
+		case bb.Pieces[Rook].Has(start):
+			targets = RookAttacks(start, occupied)
+		case bb.Pieces[Queen].Has(start):
+			targets = QueenAttacks(start, occupied)
+		}
+		moves = appendTargetMoves(moves, start, targets&^own&piece_mask, enemy)
+	}
+
+	moves = appendEnPassantMovesBB(moves, bb, bs, king, mask)
+	if checkers == 0 {
+		moves = appendCastleMovesBB(moves, bb, bs, danger)
+	}
+	return moves
+}
+
+func GenerateLegalMovesBB(gp *GamePosition) []Move {
+	return AppendLegalMovesBB(make([]Move, 0, 64), gp)
+}
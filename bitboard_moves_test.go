@@ -0,0 +1,63 @@
+package main
+
+import "testing"
+
+// both generators should produce the same set of moves in every node of the tree
+func checkGeneratorsWalk(gp *GamePosition, depth int, t *testing.T) {
+	naive := GenerateLegalMoves(&gp.Board, gp.State)
+	fast := GenerateLegalMovesBB(gp)
+	if len(naive) != len(fast) {
+		t.Fatalf("%s: %d moves, expected %d", gp.String(), len(fast), len(naive))
+	}
+	for _, move := range fast {
+		if expected, found := findMove(naive, move.Compact()); !found || expected != move {
+			t.Fatalf("%s: move %v with flags %x is not generated by GenerateLegalMoves", gp.String(), move, move.GetFlags())
+		}
+	}
+	if depth == 0 {
+		return
+	}
+	for _, move := range fast {
+		undo := gp.MakeMove(move)
+		checkGeneratorsWalk(gp, depth-1, t)
+		gp.UnmakeMove(move, undo)
+	}
+}
+
+func TestGenerateLegalMovesBB(t *testing.T) {
+	for _, fen := range []string{perftInitial, perftKiwipete, perftPos3, perftPos4, perftPos5, perftPos6,
+		"8/8/8/KPp4r/8/8/8/7k w - c6 0 1",   // en passant exposes the king on the rank
+		"8/8/3k4/8/2pP4/8/8/5K2 b - d3 0 1", // en passant captures the checking pawn
+		"8/8/8/8/k2Pp2Q/8/8/3K4 b - d3 0 1", // en passant would discover check along the rank
+	} {
+		gp, er := ParseFEN(fen)
+		assert_er(er, t)
+		checkGeneratorsWalk(&gp, 2, t)
+	}
+}
+
+func TestPerftBB(t *testing.T) {
+	cases := []struct {
+		fen   string
+		nodes []uint64
+	}{
+		{perftInitial, []uint64{20, 400, 8902, 197281, 4865609}},
+		{perftKiwipete, []uint64{48, 2039, 97862, 4085603}},
+		{perftPos3, []uint64{14, 191, 2812, 43238, 674624}},
+		{perftPos4, []uint64{6, 264, 9467, 422333}},
+		{perftPos5, []uint64{44, 1486, 62379, 2103487}},
+		{perftPos6, []uint64{46, 2079, 89890, 3894594}},
+	}
+	for _, c := range cases {
+		gp, er := ParseFEN(c.fen)
+		assert_er(er, t)
+		for i, expected := range c.nodes {
+			if testing.Short() && expected > 100000 {
+				break
+			}
+			if nodes := PerftBB(&gp, i+1); nodes != expected {
+				t.Errorf("%s depth %d: %d nodes, expected %d", c.fen, i+1, nodes, expected)
+			}
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import "testing"
+
+func TestBitboardHelpers(t *testing.T) {
+	b := SquareBB(MakePos(0, 0)) | SquareBB(MakePos(3, 7)) | SquareBB(MakePos(7, 4))
+	assert_equal(b.Count(), 3, t)
+	assert_equal(b.LSB(), MakePos(0, 0), t)
+	var squares []Position
+	for set := b; set != 0; {
+		squares = append(squares, set.PopLSB())
+	}
+	assert_equal(len(squares), 3, t)
+	assert_equal(squares[2], MakePos(7, 4), t)
+
+	assert_equal(SquareBB(MakePos(3, 7)).East(), 0, t)
+	assert_equal(SquareBB(MakePos(3, 0)).West(), 0, t)
+	assert_equal(SquareBB(MakePos(7, 3)).North(), 0, t)
+	assert_equal(SquareBB(MakePos(3, 3)).NorthEast(), SquareBB(MakePos(4, 4)), t)
+	assert_equal(SquareBB(MakePos(3, 3)).NorthWest(), SquareBB(MakePos(4, 2)), t)
+	assert_equal(SquareBB(MakePos(3, 3)).SouthEast(), SquareBB(MakePos(2, 4)), t)
+	assert_equal(SquareBB(MakePos(3, 3)).SouthWest(), SquareBB(MakePos(2, 2)), t)
+	assert_equal(SquareBB(MakePos(0, 7)).NorthEast(), 0, t)
+	assert_equal(SquareBB(MakePos(7, 0)).SouthWest(), 0, t)
+}
+
+func TestBitBoardsConversion(t *testing.T) {
+	for _, fen := range []string{perftInitial, perftKiwipete, perftPos4, "8/8/8/8/8/8/8/8 w - - 0 1"} {
+		board, _ := makeTestPosition(fen, t)
+		bb := MakeBitBoards(&board)
+		assert_equal(bb.Board(), board, t)
+		for i, piece := range board {
+			assert_equal(bb.GetPiece(Position(i)), piece, t)
+		}
+	}
+
+	board := MakeInitialBoard()
+	bb := MakeBitBoards(&board)
+	assert_equal(bb.Occupied(), Rank1|Rank1<<8|Rank8|Rank8>>8, t)
+	assert_equal(bb.PieceSet(W_Pawn), Rank1<<8, t)
+	assert_equal(bb.PieceSet(B_King), SquareBB(MakePos(7, 4)), t)
+	bb.SetPiece(MakePos(7, 4), W_Queen)
+	assert_equal(bb.GetPiece(MakePos(7, 4)), W_Queen, t)
+	assert_equal(bb.PieceSet(B_King), 0, t)
+}
+
+func checkBitBoardsWalk(gp *GamePosition, depth int, t *testing.T) {
+	if MakeBitBoards(&gp.Board) != gp.Bits {
+		t.Fatalf("%s: bitboards are out of sync", gp.String())
+	}
+	if depth == 0 {
+		return
+	}
+	for _, move := range GenerateLegalMoves(&gp.Board, gp.State) {
+		undo := gp.MakeMove(move)
+		checkBitBoardsWalk(gp, depth-1, t)
+		gp.UnmakeMove(move, undo)
+	}
+}
+
+func TestBitBoardsSync(t *testing.T) {
+	for _, fen := range []string{perftKiwipete, perftPos3, perftPos4, perftPos5} {
+		gp, er := ParseFEN(fen)
+		assert_er(er, t)
+		checkBitBoardsWalk(&gp, 3, t)
+	}
+}
@@ -2,7 +2,6 @@ package main
 
 import (
 	"errors"
-	"fmt"
 	"strconv"
 )
 
@@ -11,7 +10,7 @@ const BoardSize int8 = 8
 type Board [BoardSize * BoardSize]Piece
 
 func CheckBoardPos(r, c int8) bool {
-	if r < 0 || BoardSize <= r || c < 0 || BoardSize <= r {
+	if r < 0 || BoardSize <= r || c < 0 || BoardSize <= c {
 		return false
 		// return errors.New("Position outside the board")
 	}
@@ -35,7 +34,7 @@ func (board *Board) GetPiece(pos Position) Piece {
 }
 
 func (board *Board) CheckedGetPiece(pos Position) (Piece, error) {
-	if CheckBoardPos(pos.GetRow(), pos.GetCol()) {
+	if !CheckBoardPos(pos.GetRow(), pos.GetCol()) {
 		return NoPiece, errors.New("Position outside the board")
 	}
 	return board.GetPiece(pos), nil
@@ -78,22 +77,25 @@ func MakeBoardFromFEN(fen string) (Board, error) {
 	var res Board
 	var r int = 7
 	var c int = 0
-	for _, s := range fen {
+	for i, s := range fen {
 		if s == '/' {
 			if c != int(BoardSize) {
-				return res, errors.New(fmt.Sprintf("error not all squares of row %d are defined", r+1))
+				return res, newFENError(FENFieldPlacement, i, "not all squares of row %d are defined", r+1)
+			}
+			if r == 0 {
+				return res, newFENError(FENFieldPlacement, i, "more than %d rows", BoardSize)
 			}
 			r--
 			c = 0
 			continue
 		}
 
-		if n_spaces := int(s) - '0'; 0 < n_spaces && n_spaces < 10 {
-			for i := 0; i < n_spaces; i++ {
-				er := res.CheckedSetPiece(MakePos(r, c), NoPiece)
-				if er != nil {
-					return res, er
-				}
+		if n_spaces := int(s) - '0'; 0 < n_spaces && n_spaces <= int(BoardSize) {
+			if c+n_spaces > int(BoardSize) {
+				return res, newFENError(FENFieldPlacement, i, "row %d has more than %d squares", r+1, BoardSize)
+			}
+			for j := 0; j < n_spaces; j++ {
+				res.SetPiece(MakePos(r, c), NoPiece)
 				c++
 			}
 			continue
@@ -101,12 +103,24 @@ func MakeBoardFromFEN(fen string) (Board, error) {
 
 		p, er := MakePieceFromRune(s)
 		if er != nil {
-			return res, er
+			return res, &FENError{FENFieldPlacement, i, er.Error(), er}
+		}
+		if c >= int(BoardSize) {
+			return res, newFENError(FENFieldPlacement, i, "row %d has more than %d squares", r+1, BoardSize)
+		}
+		if er := res.CheckedSetPiece(MakePos(r, c), p); er != nil {
+			return res, &FENError{FENFieldPlacement, i, er.Error(), er}
 		}
-		res.CheckedSetPiece(MakePos(r, c), p)
 		c++
 	}
 
+	if r != 0 {
+		return res, newFENError(FENFieldPlacement, len(fen), "only %d of %d rows are defined", int(BoardSize)-r, BoardSize)
+	}
+	if c != int(BoardSize) {
+		return res, newFENError(FENFieldPlacement, len(fen), "not all squares of row %d are defined", r+1)
+	}
+
 	return res, nil
 }
 
@@ -1,22 +1,20 @@
 package main
 
 import (
-	"errors"
 	"strconv"
-	"strings"
 )
 
-type BoardState uint32 // turn, castle, en passant, moves since capture or pawn advance
+type BoardState uint32 // turn, castle, en passant, half moves since capture or pawn advance
 
 const (
-	bsTurnMask    BoardState = 0b_100000_000000_000000
-	bsKMask       BoardState = 0b_010000_000000_000000
-	bsQMask       BoardState = 0b_001000_000000_000000
-	bskMask       BoardState = 0b_000100_000000_000000
-	bsqMask       BoardState = 0b_000010_000000_000000
-	bsIsEnPosMask BoardState = 0b_000001_000000_000000
-	bsEnPosMask   BoardState = 0b_000000_111111_000000
-	bsHMovesMask  BoardState = 0b_000000_000000_111111
+	bsTurnMask    BoardState = 0b_100000_000000_00000000
+	bsKMask       BoardState = 0b_010000_000000_00000000
+	bsQMask       BoardState = 0b_001000_000000_00000000
+	bskMask       BoardState = 0b_000100_000000_00000000
+	bsqMask       BoardState = 0b_000010_000000_00000000
+	bsIsEnPosMask BoardState = 0b_000001_000000_00000000
+	bsEnPosMask   BoardState = 0b_000000_111111_00000000
+	bsHMovesMask  BoardState = 0b_000000_000000_11111111
 )
 
 func (bs BoardState) Set_Turn(is_white bool) BoardState {
@@ -86,11 +84,11 @@ func (bs BoardState) Get_IsEnPos() bool {
 }
 
 func (bs BoardState) Set_EnPos(pos Position) BoardState {
-	return (bs &^ bsEnPosMask) | BoardState(pos)<<6
+	return (bs &^ bsEnPosMask) | BoardState(pos)<<8
 }
 
 func (bs BoardState) Get_EnPos() Position {
-	return Position(bs & bsEnPosMask >> 6)
+	return Position(bs & bsEnPosMask >> 8)
 }
 
 func (bs BoardState) Set_HMoves(h_moves uint8) BoardState {
@@ -110,7 +108,7 @@ func MakeInitialBoardState() BoardState {
 	res = res.Set_q(true)
 	res = res.Set_IsEnPos(false)
 	// no need to set ep passant position
-	res = res.Set_HMoves(1)
+	res = res.Set_HMoves(0)
 	return res
 }
 
@@ -166,7 +164,7 @@ func (bs BoardState) setTurnFromFen(fen string) (BoardState, error) {
 	case "b":
 		return bs.Set_Turn(false), nil
 	}
-	return bs, errors.New("unknown turn type")
+	return bs, newFENError(FENFieldSide, 0, "unknown turn type %q", fen)
 }
 
 func (bs BoardState) setCastleFromFen(fen string) (BoardState, error) {
@@ -177,29 +175,26 @@ func (bs BoardState) setCastleFromFen(fen string) (BoardState, error) {
 	if fen == "-" {
 		return bs, nil
 	}
-	error_rcv := errors.New("repeated castle value")
-	for _, c := range fen {
+	for i, c := range fen {
+		var is_set bool
 		switch c {
 		case 'K':
-			if bs.Get_K() {
-				return bs, error_rcv
-			}
+			is_set = bs.Get_K()
 			bs = bs.Set_K(true)
 		case 'Q':
-			if bs.Get_Q() {
-				return bs, error_rcv
-			}
+			is_set = bs.Get_Q()
 			bs = bs.Set_Q(true)
 		case 'k':
-			if bs.Get_k() {
-				return bs, error_rcv
-			}
+			is_set = bs.Get_k()
 			bs = bs.Set_k(true)
 		case 'q':
-			if bs.Get_q() {
-				return bs, error_rcv
-			}
+			is_set = bs.Get_q()
 			bs = bs.Set_q(true)
+		default:
+			return bs, newFENError(FENFieldCastling, i, "unknown castle value %q", c)
+		}
+		if is_set {
+			return bs, newFENError(FENFieldCastling, i, "repeated castle value %q", c)
 		}
 	}
 	return bs, nil
@@ -213,7 +208,7 @@ func (bs BoardState) setEnPosFromFen(fen string) (BoardState, error) {
 	bs = bs.Set_IsEnPos(true)
 	pos, er := MakePiecePosFromFEN(fen)
 	if er != nil {
-		return bs, er
+		return bs, &FENError{FENFieldEnPassant, 0, er.Error(), er}
 	}
 	return bs.Set_EnPos(pos), nil
 }
@@ -221,41 +216,38 @@ func (bs BoardState) setEnPosFromFen(fen string) (BoardState, error) {
 func (bs BoardState) setHMovesFromFen(fen string) (BoardState, error) {
 	hmoves, er := strconv.Atoi(fen)
 	if er != nil {
-		return bs, er
+		return bs, &FENError{FENFieldHalfMoveClock, 0, "not a number", er}
 	}
-	if hmoves < 1 || hmoves > 50 {
-		return bs, errors.New("Impossible number of half moves")
+	if hmoves < 0 || hmoves > int(bsHMovesMask) {
+		return bs, newFENError(FENFieldHalfMoveClock, 0, "impossible number of half moves %d", hmoves)
 	}
 	return bs.Set_HMoves(uint8(hmoves)), nil
 }
 
-func MakeBoardStateFromFEN(fen string) (BoardState, error) {
+// board state from turn, castle, en passant and optional half-move clock fields,
+// offsets are used to report errors relative to the whole FEN
+func makeBoardStateFromFields(fields []string, offsets []int) (BoardState, error) {
 	var res BoardState
-	fen_params := strings.Split(fen, " ")
-	if len(fen_params) != 4 {
-		return res, errors.New("wrong number of parameters")
+	setters := [...]func(BoardState, string) (BoardState, error){
+		BoardState.setTurnFromFen,
+		BoardState.setCastleFromFen,
+		BoardState.setEnPosFromFen,
+		BoardState.setHMovesFromFen,
 	}
 	var er error
-
-	res, er = res.setTurnFromFen(fen_params[0])
-	if er != nil {
-		return res, er
-	}
-
-	res, er = res.setCastleFromFen(fen_params[1])
-	if er != nil {
-		return res, er
-	}
-
-	res, er = res.setEnPosFromFen(fen_params[2])
-	if er != nil {
-		return res, er
+	for i, field := range fields {
+		res, er = setters[i](res, field)
+		if er != nil {
+			return res, shiftFENError(er, offsets[i])
+		}
 	}
+	return res, nil
+}
 
-	res, er = res.setHMovesFromFen(fen_params[3])
-	if er != nil {
-		return res, er
+func MakeBoardStateFromFEN(fen string) (BoardState, error) {
+	fields, offsets := splitFENFields(fen)
+	if len(fields) != 4 {
+		return 0, fenFieldCountError(fen, fields, offsets, 4, FENFieldSide)
 	}
-
-	return res, nil
+	return makeBoardStateFromFields(fields, offsets)
 }
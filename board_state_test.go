@@ -39,7 +39,7 @@ func TestInitialBoardState(t *testing.T) {
 		t.Error("wrong en passant")
 	}
 
-	if bs.Get_HMoves() != 1 {
+	if bs.Get_HMoves() != 0 {
 		t.Error("wrong half move count")
 	}
 }
@@ -52,7 +52,7 @@ func TestBoardState(t *testing.T) {
 					for _, q := range [2]bool{true, false} {
 						for _, is_en := range [2]bool{true, false} {
 							for _, en_pos := range [3]Position{MakePos(1, 2), MakePos(7, 0), MakePos(7, 7)} {
-								for _, hmove := range [3]uint8{1, 13, 50} {
+								for _, hmove := range [4]uint8{0, 1, 13, 150} {
 									var bs BoardState
 									bs = bs.Set_Turn(is_w)
 									bs = bs.Set_K(K)
@@ -94,7 +94,7 @@ func TestBoardStateFEN(t *testing.T) {
 	bs := MakeInitialBoardState()
 	var fen string
 	fen = bs.FEN()
-	assert_equal(fen, "w KQkq - 1", t)
+	assert_equal(fen, "w KQkq - 0", t)
 	bs_copy, er := MakeBoardStateFromFEN(fen)
 	assert_er(er, t)
 	assert_equal(bs, bs_copy, t)
@@ -103,7 +103,7 @@ func TestBoardStateFEN(t *testing.T) {
 	bs = bs.Set_IsEnPos(true)
 	bs = bs.Set_EnPos(MakePos(1, 2))
 	fen = bs.FEN()
-	assert_equal(fen, "w Kkq c2 1", t)
+	assert_equal(fen, "w Kkq c2 0", t)
 	bs_copy, er = MakeBoardStateFromFEN(fen)
 	assert_er(er, t)
 	assert_equal(bs, bs_copy, t)
@@ -0,0 +1,292 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// score of mate in n moves in the thinking output
+const cecpMateScore = 100000
+
+// search running in the background, its move is played unless it is aborted
+type cecpSearch struct {
+	cancel  context.CancelFunc
+	done    chan struct{}
+	aborted atomic.Bool
+}
+
+// state of the Chess Engine Communication Protocol (XBoard) session
+type CECPEngine struct {
+	out    io.Writer
+	out_mu sync.Mutex
+	game   *Game // changed by the search goroutine, so it is accessed only when no search runs
+	search *cecpSearch
+	tt     *TranspositionTable
+
+	force bool        // engine only records the moves
+	post  atomic.Bool // send thinking output, read by the search goroutine
+
+	// time control
+	moves_per_session int
+	base              time.Duration
+	inc               time.Duration
+	move_time         time.Duration // st, exact time per move
+	depth             int           // sd
+	time_left         time.Duration // time, the engine clock
+}
+
+func MakeCECPEngine(out io.Writer) *CECPEngine {
+	e := &CECPEngine{out: out, tt: MakeTranspositionTable(DefaultTTSizeMB)}
+	e.newGame()
+	return e
+}
+
+func (e *CECPEngine) printf(format string, args ...any) {
+	e.out_mu.Lock()
+	defer e.out_mu.Unlock()
+	fmt.Fprintf(e.out, format+"\n", args...)
+}
+
+func (e *CECPEngine) newGame() {
+	e.game = NewGame()
+	e.tt.Clear()
+	e.force = false
+	e.depth = 0
+	e.move_time = 0
+	e.time_left = e.base
+}
+
+// handles one command, returns true on quit
+func (e *CECPEngine) Handle(line string) bool {
+	tokens := strings.Fields(line)
+	if len(tokens) == 0 {
+		return false
+	}
+	args := tokens[1:]
+	switch tokens[0] {
+	case "xboard", "accepted", "rejected", "random", "hard", "easy", "computer", "name", "rating", "ics", "otim", "draw":
+	case "protover":
+		e.printf("feature myname=\"%s\" setboard=1 usermove=1 ping=1 playother=1 memory=1 san=0 colors=0 sigint=0 sigterm=0 analyze=0 done=1", engineName)
+	case "new":
+		e.abortSearch()
+		e.newGame()
+	case "setboard":
+		e.abortSearch()
+		gp, er := ParseFEN(strings.Join(args, " "))
+		if er != nil {
+			e.printf("tellusererror Illegal position: %s", er.Error())
+			return false
+		}
+		e.game = MakeGame(gp)
+	case "usermove":
+		if len(args) > 0 {
+			e.userMove(args[0])
+		}
+	case "go":
+		e.abortSearch()
+		e.force = false
+		e.think()
+	case "playother":
+		e.abortSearch()
+		e.force = false
+	case "force":
+		e.abortSearch()
+		e.force = true
+	case "result":
+		e.abortSearch()
+		e.force = true
+	case "?":
+		e.moveNow()
+	case "level":
+		e.setLevel(args)
+	case "st":
+		if len(args) > 0 {
+			seconds, _ := strconv.ParseFloat(args[0], 64)
+			e.move_time = time.Duration(seconds * float64(time.Second))
+		}
+	case "sd":
+		if len(args) > 0 {
+			e.depth, _ = strconv.Atoi(args[0])
+		}
+	case "time":
+		if len(args) > 0 {
+			centiseconds, _ := strconv.Atoi(args[0])
+			e.time_left = time.Duration(centiseconds) * 10 * time.Millisecond
+		}
+	case "undo":
+		e.abortSearch()
+		e.game.Undo()
+	case "remove":
+		e.abortSearch()
+		e.game.Undo()
+		e.game.Undo()
+	case "post":
+		e.post.Store(true)
+	case "nopost":
+		e.post.Store(false)
+	case "memory": // total memory in megabytes, all of it goes to the transposition table
+		if len(args) > 0 {
+			size_mb, _ := strconv.Atoi(args[0])
+			e.abortSearch()
+			e.tt.Resize(min(max(size_mb, 1), maxTTSizeMB))
+		}
+	case "ping":
+		e.printf("pong %s", strings.Join(args, " "))
+	case "quit":
+		e.abortSearch()
+		return true
+	default:
+		// moves are sent without usermove by interfaces which ignore the feature
+		if _, er := parseUCIMoveText(tokens[0]); er == nil {
+			e.userMove(tokens[0])
+			return false
+		}
+		e.printf("Error (unknown command): %s", tokens[0])
+	}
+	return false
+}
+
+// level <moves per session> <base minutes or min:sec> <increment seconds>
+func (e *CECPEngine) setLevel(args []string) {
+	if len(args) < 3 {
+		e.printf("Error (bad arguments): level")
+		return
+	}
+	e.moves_per_session, _ = strconv.Atoi(args[0])
+	minutes, seconds, _ := strings.Cut(args[1], ":")
+	m, _ := strconv.Atoi(minutes)
+	s, _ := strconv.Atoi(seconds)
+	e.base = time.Duration(m)*time.Minute + time.Duration(s)*time.Second
+	inc, _ := strconv.ParseFloat(args[2], 64)
+	e.inc = time.Duration(inc * float64(time.Second))
+	e.move_time = 0
+	e.time_left = e.base
+}
+
+func (e *CECPEngine) userMove(text string) {
+	e.abortSearch()
+	move, er := ParseUCIMove(text, &e.game.Position.Board, e.game.Position.State)
+	if er == nil {
+		er = e.game.Play(move)
+	}
+	if er != nil {
+		e.printf("Illegal move: %s", text)
+		return
+	}
+	if e.printResult() || e.force {
+		return
+	}
+	e.think()
+}
+
+// prints the result if the game is finished and returns true in that case
+func (e *CECPEngine) printResult() bool {
+	status := e.game.Status()
+	switch {
+	case !status.IsFinished():
+		return false
+	case status == GameCheckmate && e.game.Position.State.Get_Turn():
+		e.printf("0-1 {Black mates}")
+	case status == GameCheckmate:
+		e.printf("1-0 {White mates}")
+	default:
+		e.printf("1/2-1/2 {%s}", status)
+	}
+	return true
+}
+
+// time for the search of the side to move
+func (e *CECPEngine) searchTime() time.Duration {
+	if e.move_time > 0 {
+		return e.move_time
+	}
+	if e.time_left <= 0 {
+		return 0
+	}
+	moves_to_go := 0
+	if e.moves_per_session > 0 {
+		moves_to_go = e.moves_per_session - (int(e.game.Position.FullMoves)-1)%e.moves_per_session
+	}
+	return AllocateTime(e.time_left, e.inc, moves_to_go)
+}
+
+// starts the search for the side to move, its move is played when it finishes
+func (e *CECPEngine) think() {
+	if e.game.Status().IsFinished() {
+		e.printResult()
+		return
+	}
+	limits := SearchLimits{Depth: e.depth, Time: e.searchTime()}
+	ctx, cancel := context.WithCancel(context.Background())
+	search := &cecpSearch{cancel: cancel, done: make(chan struct{})}
+	e.search = search
+	gp := e.game.Position
+	go func() {
+		defer close(search.done)
+		res := Search(ctx, gp, e.tt, limits, e.printThinking)
+		cancel()
+		if search.aborted.Load() || res.Move == 0 {
+			return
+		}
+		e.game.Play(res.Move)
+		e.printf("move %s", res.Move.UCI())
+		e.printResult()
+	}()
+}
+
+// stops the search without playing its move
+func (e *CECPEngine) abortSearch() {
+	if e.search == nil {
+		return
+	}
+	e.search.aborted.Store(true)
+	e.search.cancel()
+	<-e.search.done
+	e.search = nil
+}
+
+// stops the search and plays the best move found so far
+func (e *CECPEngine) moveNow() {
+	if e.search == nil {
+		return
+	}
+	e.search.cancel()
+	<-e.search.done
+	e.search = nil
+}
+
+// waits until the running search finishes by itself
+func (e *CECPEngine) waitSearch() {
+	if e.search != nil {
+		<-e.search.done
+	}
+}
+
+func cecpScore(score int) int {
+	if !IsMateScore(score) {
+		return score
+	}
+	distance := MateDistance(score)
+	if distance > 0 {
+		return cecpMateScore + distance
+	}
+	return -cecpMateScore + distance
+}
+
+// thinking output: ply score time nodes pv, time is in centiseconds
+func (e *CECPEngine) printThinking(info SearchInfo) {
+	if !e.post.Load() {
+		return
+	}
+	pv := make([]string, len(info.PV))
+	for i, move := range info.PV {
+		pv[i] = move.UCI()
+	}
+	e.printf("%d %d %d %d %s", info.Depth, cecpScore(info.Score), info.Time.Milliseconds()/10, info.Nodes, strings.Join(pv, " "))
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCECPFeatures(t *testing.T) {
+	var sb strings.Builder
+	e := MakeCECPEngine(&sb)
+	e.Handle("xboard")
+	e.Handle("protover 2")
+	e.Handle("ping 7")
+	e.Handle("bogus")
+	out := sb.String()
+	assert_contains(out, "feature myname=\"enginsant\" ", t)
+	assert_contains(out, " usermove=1 ", t)
+	assert_contains(out, "done=1\npong 7\n", t)
+	assert_contains(out, "Error (unknown command): bogus\n", t)
+}
+
+func TestCECPPlay(t *testing.T) {
+	var sb strings.Builder
+	e := MakeCECPEngine(&sb)
+	e.Handle("new")
+	e.Handle("force")
+	e.Handle("usermove e2e4")
+	e.Handle("e7e5")
+	assert_equal(len(e.game.Moves()), 2, t)
+	assert_equal(sb.String(), "", t)
+
+	e.Handle("usermove e1e3")
+	assert_contains(sb.String(), "Illegal move: e1e3\n", t)
+
+	e.Handle("remove")
+	assert_equal(len(e.game.Moves()), 0, t)
+
+	e.Handle("setboard 6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1")
+	e.Handle("post")
+	e.Handle("sd 1")
+	e.Handle("go")
+	e.waitSearch()
+	out := sb.String()
+	assert_contains(out, "1 100001 ", t)
+	assert_contains(out, "move a1a8\n1-0 {White mates}\n", t)
+	assert_equal(e.game.Status(), GameCheckmate, t)
+}
+
+func TestCECPReply(t *testing.T) {
+	var sb strings.Builder
+	e := MakeCECPEngine(&sb)
+	e.Handle("new")
+	e.Handle("level 40 0:30 0")
+	assert_equal(e.base, 30*time.Second, t)
+	e.Handle("time 500")
+	assert_equal(e.searchTime(), AllocateTime(5*time.Second, 0, 40), t)
+	e.Handle("st 1")
+	assert_equal(e.searchTime(), time.Second, t)
+	e.Handle("st 0.05")
+
+	e.Handle("usermove d2d4")
+	e.waitSearch()
+	assert_contains(sb.String(), "move ", t)
+	assert_equal(len(e.game.Moves()), 2, t)
+
+	e.Handle("undo")
+	assert_equal(len(e.game.Moves()), 1, t)
+	e.Handle("quit")
+}
+
+func TestRunProtocol(t *testing.T) {
+	var sb strings.Builder
+	assert_er(runProtocol(strings.NewReader("\nxboard\nprotover 2\nquit\n"), &sb), t)
+	assert_contains(sb.String(), "feature ", t)
+
+	sb.Reset()
+	assert_er(runProtocol(strings.NewReader("uci\n"), &sb), t)
+	assert_contains(sb.String(), "uciok\n", t)
+}
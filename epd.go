@@ -0,0 +1,317 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type EPDOperation struct {
+	Opcode   string
+	Operands []string // without quotes
+}
+
+// EPD line: position without move counters followed by operations,
+// hmvc and fmvn operations set the move counters of Position
+type EPDRecord struct {
+	Position   GamePosition
+	Operations []EPDOperation
+}
+
+// error of EPD parsing, Line is 1 based and 0 for a single parsed record,
+// Offset is the byte offset in the line
+type EPDError struct {
+	Line   int
+	Offset int
+	Reason string
+	Err    error // underlying error if any
+}
+
+func (e *EPDError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("EPD column %d: %s", e.Offset, e.Reason)
+	}
+	return fmt.Sprintf("EPD line %d, column %d: %s", e.Line, e.Offset, e.Reason)
+}
+
+func (e *EPDError) Unwrap() error {
+	return e.Err
+}
+
+// opcodes with a single string operand which is always quoted
+func isEPDStringOpcode(opcode string) bool {
+	switch opcode {
+	case "id", "eco", "nic", "tcgs", "tcri", "tcsi":
+		return true
+	}
+	return len(opcode) == 2 && (opcode[0] == 'c' || opcode[0] == 'v') && '0' <= opcode[1] && opcode[1] <= '9'
+}
+
+// opcodes with a move sequence played one after another, other move opcodes are alternatives
+func isEPDSequenceOpcode(opcode string) bool {
+	return opcode == "pv" || opcode == "sv"
+}
+
+func isEPDOpcodeRune(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '_'
+}
+
+// returns the offset of the end of the first n space separated fields
+func epdFieldsEnd(s string, n int) int {
+	i := 0
+	for ; n > 0; n-- {
+		for i < len(s) && (s[i] == ' ' || s[i] == '\t') {
+			i++
+		}
+		for i < len(s) && s[i] != ' ' && s[i] != '\t' {
+			i++
+		}
+	}
+	return i
+}
+
+func ParseEPD(line string) (EPDRecord, error) {
+	var res EPDRecord
+	end := epdFieldsEnd(line, 4)
+	gp, er := ParseFEN(line[:end])
+	if er != nil {
+		res := &EPDError{Reason: "bad position", Err: er}
+		if fe, ok := er.(*FENError); ok {
+			res.Offset = fe.Offset
+			res.Reason = fmt.Sprintf("%s field: %s", fe.Field, fe.Reason)
+		}
+		return EPDRecord{}, res
+	}
+	res.Position = gp
+
+	s := line
+	i := end
+	for {
+		for i < len(s) && (s[i] == ' ' || s[i] == '\t') {
+			i++
+		}
+		if i == len(s) {
+			break
+		}
+		start := i
+		for i < len(s) && isEPDOpcodeRune(s[i]) {
+			i++
+		}
+		if i == start || !('a' <= s[start] && s[start] <= 'z' || 'A' <= s[start] && s[start] <= 'Z') {
+			return res, &EPDError{Offset: start, Reason: "bad opcode"}
+		}
+		op := EPDOperation{Opcode: s[start:i]}
+		for {
+			for i < len(s) && (s[i] == ' ' || s[i] == '\t') {
+				i++
+			}
+			if i == len(s) || s[i] == ';' {
+				i = min(i+1, len(s)) // the last operation may miss ';'
+				break
+			}
+			start = i
+			if s[i] == '"' {
+				quote_end := strings.IndexByte(s[i+1:], '"')
+				if quote_end < 0 {
+					return res, &EPDError{Offset: start, Reason: "unterminated string"}
+				}
+				op.Operands = append(op.Operands, s[i+1:i+1+quote_end])
+				i += quote_end + 2
+				continue
+			}
+			for i < len(s) && s[i] != ' ' && s[i] != '\t' && s[i] != ';' {
+				i++
+			}
+			op.Operands = append(op.Operands, s[start:i])
+		}
+		res.Operations = append(res.Operations, op)
+	}
+
+	if er := res.applyMoveCounters(); er != nil {
+		return res, er
+	}
+	return res, nil
+}
+
+func (r *EPDRecord) applyMoveCounters() error {
+	if value, found, er := r.intOperand("hmvc"); found {
+		if er != nil || value < 0 || value > 0xff {
+			return &EPDError{Reason: "bad hmvc operand", Err: er}
+		}
+		r.Position.State = r.Position.State.Set_HMoves(uint8(value))
+	}
+	if value, found, er := r.intOperand("fmvn"); found {
+		if er != nil || value < 1 || value > 0xffff {
+			return &EPDError{Reason: "bad fmvn operand", Err: er}
+		}
+		r.Position.FullMoves = uint16(value)
+	}
+	return nil
+}
+
+// record of the position, move counters are kept in hmvc and fmvn if they are not default
+func MakeEPDRecord(gp GamePosition) EPDRecord {
+	res := EPDRecord{Position: gp}
+	if hmoves := gp.State.Get_HMoves(); hmoves != 0 {
+		res.SetInt("hmvc", int(hmoves))
+	}
+	if gp.FullMoves != 1 {
+		res.SetInt("fmvn", int(gp.FullMoves))
+	}
+	return res
+}
+
+// operands of the operation and true if the operation is present
+func (r *EPDRecord) Operation(opcode string) ([]string, bool) {
+	for _, op := range r.Operations {
+		if op.Opcode == opcode {
+			return op.Operands, true
+		}
+	}
+	return nil, false
+}
+
+// replaces the operands of the operation or appends the operation
+func (r *EPDRecord) SetOperation(opcode string, operands ...string) {
+	for i := range r.Operations {
+		if r.Operations[i].Opcode == opcode {
+			r.Operations[i].Operands = operands
+			return
+		}
+	}
+	r.Operations = append(r.Operations, EPDOperation{opcode, operands})
+}
+
+func (r *EPDRecord) RemoveOperation(opcode string) {
+	for i := range r.Operations {
+		if r.Operations[i].Opcode == opcode {
+			r.Operations = append(r.Operations[:i], r.Operations[i+1:]...)
+			return
+		}
+	}
+}
+
+// first operand, e.g. of id or c0
+func (r *EPDRecord) GetString(opcode string) (string, bool) {
+	operands, found := r.Operation(opcode)
+	if !found || len(operands) == 0 {
+		return "", false
+	}
+	return operands[0], true
+}
+
+func (r *EPDRecord) SetString(opcode, value string) {
+	r.SetOperation(opcode, value)
+}
+
+func (r *EPDRecord) intOperand(opcode string) (value int, found bool, er error) {
+	s, found := r.GetString(opcode)
+	if !found {
+		return 0, false, nil
+	}
+	value, er = strconv.Atoi(s)
+	return value, true, er
+}
+
+// integer operand, e.g. of acd, ce or dm, false if it is missing or not a number
+func (r *EPDRecord) GetInt(opcode string) (int, bool) {
+	value, found, er := r.intOperand(opcode)
+	return value, found && er == nil
+}
+
+func (r *EPDRecord) SetInt(opcode string, value int) {
+	r.SetOperation(opcode, strconv.Itoa(value))
+}
+
+// moves of SAN operands, e.g. of bm, am or pv, pv and sv are played one after another
+// and other opcodes are alternatives from the record position, nil if the operation is missing
+func (r *EPDRecord) GetMoves(opcode string) ([]Move, error) {
+	operands, _ := r.Operation(opcode)
+	gp := r.Position
+	var res []Move
+	for _, san := range operands {
+		move, er := ParseSAN(san, &gp.Board, gp.State)
+		if er != nil {
+			return nil, &EPDError{Reason: fmt.Sprintf("bad %s operand", opcode), Err: er}
+		}
+		res = append(res, move)
+		if isEPDSequenceOpcode(opcode) {
+			gp.MakeMove(AddMoveFlags(move, &gp.Board, gp.State))
+		}
+	}
+	return res, nil
+}
+
+func (r *EPDRecord) SetMoves(opcode string, moves []Move) {
+	gp := r.Position
+	operands := make([]string, len(moves))
+	for i, move := range moves {
+		operands[i] = ToSAN(move, &gp.Board, gp.State)
+		if isEPDSequenceOpcode(opcode) {
+			gp.MakeMove(AddMoveFlags(move, &gp.Board, gp.State))
+		}
+	}
+	r.SetOperation(opcode, operands...)
+}
+
+func (r *EPDRecord) ID() string {
+	id, _ := r.GetString("id")
+	return id
+}
+
+func (r *EPDRecord) String() string {
+	board_fen, _ := r.Position.Board.FEN()
+	state_fen := r.Position.State.FEN()
+	var sb strings.Builder
+	sb.WriteString(board_fen)
+	sb.WriteByte(' ')
+	sb.WriteString(state_fen[:strings.LastIndexByte(state_fen, ' ')]) // without half-move clock
+	for _, op := range r.Operations {
+		sb.WriteByte(' ')
+		sb.WriteString(op.Opcode)
+		for _, operand := range op.Operands {
+			sb.WriteByte(' ')
+			if isEPDStringOpcode(op.Opcode) || operand == "" || strings.ContainsAny(operand, " \t;") {
+				sb.WriteString(`"` + operand + `"`)
+			} else {
+				sb.WriteString(operand)
+			}
+		}
+		sb.WriteByte(';')
+	}
+	return sb.String()
+}
+
+// reads records line by line, empty lines are skipped
+func ReadEPD(r io.Reader) ([]EPDRecord, error) {
+	var res []EPDRecord
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		record, er := ParseEPD(text)
+		if er != nil {
+			var epd_er *EPDError
+			if errors.As(er, &epd_er) {
+				epd_er.Line = line
+			}
+			return res, er
+		}
+		res = append(res, record)
+	}
+	return res, scanner.Err()
+}
+
+func WriteEPD(w io.Writer, records []EPDRecord) error {
+	bw := bufio.NewWriter(w)
+	for i := range records {
+		bw.WriteString(records[i].String())
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const epdTestRecords = `2rr3k/pp3pp1/1nnqbN1p/3pN3/2pP4/2P3Q1/PPB4P/R4RK1 w - - bm Qg6; id "WAC.001";
+r1bq1rk1/pppp1ppp/2n2n2/2b1p3/2B1P3/2NP1N2/PPP2PPP/R1BQK2R w KQ - acd 12; ce -35; pv O-O d6 Bg5; c0 "main line; checked"; hmvc 4; fmvn 6;
+
+4k3/8/8/8/8/8/8/R3K3 w Q - am Kf2 Kd2; bm O-O-O Ra8+; id "castle"
+`
+
+func TestParseEPD(t *testing.T) {
+	records, er := ReadEPD(strings.NewReader(epdTestRecords))
+	assert_er(er, t)
+	assert_equal(len(records), 3, t)
+
+	r := &records[0]
+	assert_equal(r.ID(), "WAC.001", t)
+	moves, er := r.GetMoves("bm")
+	assert_er(er, t)
+	assert_equal(len(moves), 1, t)
+	assert_equal(moves[0].UCI(), "g3g6", t)
+	assert_equal(r.Position.String(), "2rr3k/pp3pp1/1nnqbN1p/3pN3/2pP4/2P3Q1/PPB4P/R4RK1 w - - 0 1", t)
+
+	r = &records[1]
+	acd, found := r.GetInt("acd")
+	assert_equal(found, true, t)
+	assert_equal(acd, 12, t)
+	ce, _ := r.GetInt("ce")
+	assert_equal(ce, -35, t)
+	comment, _ := r.GetString("c0")
+	assert_equal(comment, "main line; checked", t)
+	pv, er := r.GetMoves("pv")
+	assert_er(er, t)
+	assert_equal(len(pv), 3, t)
+	assert_equal(pv[0].IsKingCastle(), true, t)
+	assert_equal(pv[2].UCI(), "c1g5", t)
+	assert_equal(r.Position.State.Get_HMoves(), uint8(4), t)
+	assert_equal(r.Position.FullMoves, uint16(6), t)
+	_, found = r.GetInt("dm")
+	assert_equal(found, false, t)
+
+	r = &records[2]
+	am, er := r.GetMoves("am")
+	assert_er(er, t)
+	assert_equal(len(am), 2, t)
+	bm, er := r.GetMoves("bm")
+	assert_er(er, t)
+	assert_equal(bm[0].IsQueenCastle(), true, t)
+	assert_equal(bm[1].UCI(), "a1a8", t)
+	assert_equal(r.ID(), "castle", t)
+}
+
+func TestWriteEPD(t *testing.T) {
+	records, er := ReadEPD(strings.NewReader(epdTestRecords))
+	assert_er(er, t)
+	var sb strings.Builder
+	assert_er(WriteEPD(&sb, records), t)
+	expected := `2rr3k/pp3pp1/1nnqbN1p/3pN3/2pP4/2P3Q1/PPB4P/R4RK1 w - - bm Qg6; id "WAC.001";
+r1bq1rk1/pppp1ppp/2n2n2/2b1p3/2B1P3/2NP1N2/PPP2PPP/R1BQK2R w KQ - acd 12; ce -35; pv O-O d6 Bg5; c0 "main line; checked"; hmvc 4; fmvn 6;
+4k3/8/8/8/8/8/8/R3K3 w Q - am Kf2 Kd2; bm O-O-O Ra8+; id "castle";
+`
+	assert_equal(sb.String(), expected, t)
+}
+
+func TestEPDSetters(t *testing.T) {
+	gp, er := ParseFEN(perftKiwipete)
+	assert_er(er, t)
+	gp.FullMoves = 12
+	r := MakeEPDRecord(gp)
+	r.SetString("id", "kiwipete")
+	r.SetInt("acd", 3)
+	r.SetMoves("bm", []Move{makeTestMove("e2", "a6", NoPiece), makeTestMove("e1", "g1", NoPiece)})
+	r.SetMoves("pv", []Move{makeTestMove("e2", "a6", NoPiece), makeTestMove("b4", "c3", NoPiece)})
+	r.SetInt("acd", 4)
+	assert_equal(r.String(), "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - fmvn 12; id \"kiwipete\"; acd 4; bm Bxa6 O-O; pv Bxa6 bxc3;", t)
+
+	parsed, er := ParseEPD(r.String())
+	assert_er(er, t)
+	assert_equal(parsed.Position.String(), gp.String(), t)
+	r.RemoveOperation("pv")
+	_, found := r.Operation("pv")
+	assert_equal(found, false, t)
+
+	gp, er = ParseFEN("r1bqkb1r/pppp1ppp/2n2n2/4p3/2B1P3/5N2/PPPP1PPP/RNBQK2R w KQkq - 4 4")
+	assert_er(er, t)
+	r = MakeEPDRecord(gp)
+	r.SetMoves("pv", []Move{makeTestMove("e1", "g1", NoPiece), makeTestMove("f8", "c5", NoPiece), makeTestMove("f1", "e1", NoPiece)})
+	assert_equal(r.String(), "r1bqkb1r/pppp1ppp/2n2n2/4p3/2B1P3/5N2/PPPP1PPP/RNBQK2R w KQkq - hmvc 4; fmvn 4; pv O-O Bc5 Re1;", t)
+}
+
+func TestEPDErrors(t *testing.T) {
+	_, er := ReadEPD(strings.NewReader("\n4k3/8/8/8/8/8/8/4K3 w - - bm Ke2;\n4k3/8/8/8/8/8/8/4K3 w - x bm Ke2;"))
+	var ee *EPDError
+	if !errors.As(er, &ee) {
+		t.Fatalf("expected EPDError, got %v", er)
+	}
+	assert_equal(ee.Line, 3, t)
+	assert_equal(ee.Offset, 24, t)
+	var fe *FENError
+	assert_equal(errors.As(er, &fe), true, t)
+
+	_, er = ParseEPD(`4k3/8/8/8/8/8/8/4K3 w - - id "open`)
+	if !errors.As(er, &ee) {
+		t.Fatalf("expected EPDError, got %v", er)
+	}
+	assert_equal(ee.Offset, 29, t)
+
+	r, er := ParseEPD("4k3/8/8/8/8/8/8/4K3 w - - bm Ke7;")
+	assert_er(er, t)
+	_, er = r.GetMoves("bm")
+	var illegal *ErrorIllegalSAN
+	assert_equal(errors.As(er, &illegal), true, t)
+}
@@ -0,0 +1,22 @@
+package main
+
+// material values in centipawns indexed by piece type
+var pieceValues = [King + 1]int{
+	Pawn:   100,
+	Knight: 320,
+	Bishop: 330,
+	Rook:   500,
+	Queen:  900,
+}
+
+// static evaluation in centipawns from the point of view of the side to move
+func Evaluate(gp *GamePosition) int {
+	score := 0
+	for t := Pawn; t < King; t++ {
+		score += pieceValues[t] * (gp.Bits.PieceSet(MakePiece(t, true)).Count() - gp.Bits.PieceSet(MakePiece(t, false)).Count())
+	}
+	if !gp.State.Get_Turn() {
+		score = -score
+	}
+	return score
+}
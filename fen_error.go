@@ -0,0 +1,85 @@
+package main
+
+import "fmt"
+
+type FENField uint8
+
+const (
+	FENFieldPlacement FENField = iota
+	FENFieldSide
+	FENFieldCastling
+	FENFieldEnPassant
+	FENFieldHalfMoveClock
+	FENFieldFullMoveNumber
+)
+
+var fenFieldNames = [...]string{
+	FENFieldPlacement:      "placement",
+	FENFieldSide:           "side to move",
+	FENFieldCastling:       "castling",
+	FENFieldEnPassant:      "en passant",
+	FENFieldHalfMoveClock:  "half-move clock",
+	FENFieldFullMoveNumber: "fullmove number",
+}
+
+func (f FENField) String() string {
+	if int(f) < len(fenFieldNames) {
+		return fenFieldNames[f]
+	}
+	return "unknown"
+}
+
+// error of FEN parsing, Offset is the byte offset of the bad character in the parsed string
+type FENError struct {
+	Field  FENField
+	Offset int
+	Reason string
+	Err    error // underlying error if any
+}
+
+func (e *FENError) Error() string {
+	return fmt.Sprintf("FEN %s field, column %d: %s", e.Field, e.Offset, e.Reason)
+}
+
+func (e *FENError) Unwrap() error {
+	return e.Err
+}
+
+func newFENError(field FENField, offset int, format string, args ...any) *FENError {
+	return &FENError{Field: field, Offset: offset, Reason: fmt.Sprintf(format, args...)}
+}
+
+// shifts offset of FENError to make it relative to the string containing the parsed field
+func shiftFENError(er error, offset int) error {
+	if fe, ok := er.(*FENError); ok {
+		fe.Offset += offset
+	}
+	return er
+}
+
+// splits FEN into space separated fields and returns byte offset of each field
+func splitFENFields(fen string) (fields []string, offsets []int) {
+	start := -1
+	for i := 0; i <= len(fen); i++ {
+		if i == len(fen) || fen[i] == ' ' || fen[i] == '\t' {
+			if start >= 0 {
+				fields = append(fields, fen[start:i])
+				offsets = append(offsets, start)
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	return fields, offsets
+}
+
+// error for wrong number of fields, first_field is the kind of fields[0]
+func fenFieldCountError(fen string, fields []string, offsets []int, expected int, first_field FENField) *FENError {
+	if len(fields) < expected {
+		return newFENError(first_field+FENField(len(fields)), len(fen), "missing field, %d of %d fields given", len(fields), expected)
+	}
+	return newFENError(first_field+FENField(expected-1), offsets[expected], "unexpected field %q", fields[expected])
+}
@@ -0,0 +1,172 @@
+package main
+
+import "fmt"
+
+type playedMove struct {
+	move Move
+	undo MoveUndo
+}
+
+// game from a start position with history of played moves
+type Game struct {
+	Position GamePosition
+	start    GamePosition
+	history  []playedMove
+	redo     []Move   // undone moves, the last undone one is at the end
+	keys     []uint64 // repetition hash, keys[i] is of the position before history[i], the last one is of the current position
+}
+
+type ErrorIllegalMove struct {
+	move Move
+}
+
+func (e *ErrorIllegalMove) Error() string {
+	return fmt.Sprintf("illegal move: %v", e.move)
+}
+
+// return true if the side to move can capture en passant
+func hasEnPassantCapture(board *Board, bs BoardState) bool {
+	if !bs.Get_IsEnPos() {
+		return false
+	}
+	var id uint = 0
+	var finished = false
+	var move Move
+	for {
+		move, id, finished = NextEnPassantMove(bs.Get_EnPos(), id, board, bs.Get_Turn())
+		if finished {
+			return false
+		}
+		if IsMoveSafe(move, board, bs) {
+			return true
+		}
+	}
+}
+
+// position hash where en passant square is counted only if the capture is possible
+func repetitionHash(gp *GamePosition) uint64 {
+	bs := gp.State
+	if bs.Get_IsEnPos() && !hasEnPassantCapture(&gp.Board, bs) {
+		return gp.Hash() ^ zobristEnPassant[bs.Get_EnPos().GetCol()]
+	}
+	return gp.Hash()
+}
+
+func MakeGame(start GamePosition) *Game {
+	start.Update()
+	g := &Game{Position: start, start: start}
+	g.keys = append(g.keys, repetitionHash(&start))
+	return g
+}
+
+func NewGame() *Game {
+	return MakeGame(MakeInitialGamePosition())
+}
+
+func (g *Game) StartPosition() GamePosition {
+	return g.start
+}
+
+// moves played from the start position
+func (g *Game) Moves() []Move {
+	res := make([]Move, len(g.history))
+	for i, pm := range g.history {
+		res[i] = pm.move
+	}
+	return res
+}
+
+func (g *Game) LegalMoves() []Move {
+	return GenerateLegalMoves(&g.Position.Board, g.Position.State)
+}
+
+// plays legal move, flags of the move are ignored,
+// playing the move which was undone last keeps the rest of the redo list
+func (g *Game) Play(move Move) error {
+	legal, found := findMove(g.LegalMoves(), move.Compact())
+	if !found {
+		return &ErrorIllegalMove{move}
+	}
+	move = legal
+	if n := len(g.redo); n > 0 && g.redo[n-1] == move {
+		g.redo = g.redo[:n-1]
+	} else {
+		g.redo = g.redo[:0]
+	}
+	g.play(move)
+	return nil
+}
+
+func (g *Game) play(move Move) {
+	undo := g.Position.MakeMove(move)
+	g.history = append(g.history, playedMove{move, undo})
+	g.keys = append(g.keys, repetitionHash(&g.Position))
+}
+
+// takes back the last move, return false if there is nothing to undo
+func (g *Game) Undo() bool {
+	n := len(g.history)
+	if n == 0 {
+		return false
+	}
+	pm := g.history[n-1]
+	g.Position.UnmakeMove(pm.move, pm.undo)
+	g.history = g.history[:n-1]
+	g.keys = g.keys[:n]
+	g.redo = append(g.redo, pm.move)
+	return true
+}
+
+// plays again the last undone move, return false if there is nothing to redo
+func (g *Game) Redo() bool {
+	n := len(g.redo)
+	if n == 0 {
+		return false
+	}
+	move := g.redo[n-1]
+	g.redo = g.redo[:n-1]
+	g.play(move)
+	return true
+}
+
+// number of times the current position has occurred in the game, including now
+func (g *Game) RepetitionCount() int {
+	current := len(g.keys) - 1
+	// positions before the last capture or pawn move can not repeat
+	first := max(current-int(g.Position.State.Get_HMoves()), 0)
+	count := 0
+	for i := current; i >= first; i -= 2 {
+		if g.keys[i] == g.keys[current] {
+			count++
+		}
+	}
+	return count
+}
+
+func (g *Game) IsThreefoldRepetition() bool {
+	return g.RepetitionCount() >= 3
+}
+
+func (g *Game) IsFivefoldRepetition() bool {
+	return g.RepetitionCount() >= 5
+}
+
+// return true if 75 moves of each side were made without capture or pawn move
+func (g *Game) IsSeventyFiveMoveRule() bool {
+	return g.Position.State.Get_HMoves() >= 150
+}
+
+// game status including the draws which do not require a claim
+func (g *Game) Status() GameStatus {
+	status := GetGameStatus(&g.Position.Board, g.Position.State)
+	if status == GameCheckmate || status == GameStalemate {
+		return status
+	}
+	if g.IsSeventyFiveMoveRule() {
+		return GameSeventyFiveMoveDraw
+	}
+	if g.IsFivefoldRepetition() {
+		return GameFivefoldRepetition
+	}
+	return status
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"strconv"
+)
+
+// everything described by FEN: the board, its state and the fullmove number
+type GamePosition struct {
+	Board     Board
+	State     BoardState
+	FullMoves uint16    // starts at 1 and is incremented after each black move
+	Bits      BitBoards // kept in sync with Board by MakeMove and UnmakeMove
+	hash      uint64    // Zobrist hash, updated incrementally by MakeMove and UnmakeMove
+}
+
+func MakeGamePosition(board Board, bs BoardState, full_moves uint16) GamePosition {
+	res := GamePosition{Board: board, State: bs, FullMoves: full_moves}
+	res.Update()
+	return res
+}
+
+func MakeInitialGamePosition() GamePosition {
+	return MakeGamePosition(MakeInitialBoard(), MakeInitialBoardState(), 1)
+}
+
+func (gp *GamePosition) Hash() uint64 {
+	return gp.hash
+}
+
+// recomputes the hash and bitboards, required after Board or State were changed directly
+func (gp *GamePosition) Update() {
+	gp.Bits = MakeBitBoards(&gp.Board)
+	gp.hash = PositionHash(&gp.Board, gp.State)
+}
+
+// parses FEN with 6 fields, missing move counters are allowed (EPD-style 4 fields),
+// in that case half-move clock is 0 and fullmove number is 1
+func ParseFEN(fen string) (GamePosition, error) {
+	res := GamePosition{FullMoves: 1}
+	fields, offsets := splitFENFields(fen)
+	if len(fields) < 4 || len(fields) > 6 {
+		return res, fenFieldCountError(fen, fields, offsets, min(max(len(fields), 4), 6), FENFieldPlacement)
+	}
+	var er error
+
+	res.Board, er = MakeBoardFromFEN(fields[0])
+	if er != nil {
+		return res, shiftFENError(er, offsets[0])
+	}
+
+	state_end := min(len(fields), 5)
+	res.State, er = makeBoardStateFromFields(fields[1:state_end], offsets[1:state_end])
+	if er != nil {
+		return res, er
+	}
+
+	if len(fields) > 5 {
+		full_moves, er := strconv.Atoi(fields[5])
+		if er != nil {
+			return res, &FENError{FENFieldFullMoveNumber, offsets[5], "not a number", er}
+		}
+		if full_moves < 1 || full_moves > 0xffff {
+			return res, newFENError(FENFieldFullMoveNumber, offsets[5], "impossible fullmove number %d", full_moves)
+		}
+		res.FullMoves = uint16(full_moves)
+	}
+
+	res.Update()
+	return res, nil
+}
+
+// FEN of the position, empty board field is returned for boards with unknown pieces
+func (gp *GamePosition) String() string {
+	board_fen, _ := gp.Board.FEN()
+	return board_fen + " " + gp.State.FEN() + " " + strconv.Itoa(int(gp.FullMoves))
+}
+
+func (gp *GamePosition) MakeMove(move Move) MoveUndo {
+	piece := gp.Board.GetPiece(move.GetStart())
+	undo := MakeMove(move, &gp.Board, &gp.State)
+	gp.Bits.ToggleMove(move, piece, undo)
+	if gp.State.Get_Turn() {
+		gp.FullMoves++
+	}
+	gp.hash ^= undo.HashDelta
+	if zobristDebug {
+		checkZobristHash(gp)
+	}
+	return undo
+}
+
+func (gp *GamePosition) UnmakeMove(move Move, undo MoveUndo) {
+	UnmakeMove(move, &gp.Board, &gp.State, undo)
+	gp.Bits.ToggleMove(move, gp.Board.GetPiece(move.GetStart()), undo)
+	if !gp.State.Get_Turn() {
+		gp.FullMoves--
+	}
+	gp.hash ^= undo.HashDelta
+	if zobristDebug {
+		checkZobristHash(gp)
+	}
+}
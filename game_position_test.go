@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseFEN(t *testing.T) {
+	fens := []string{
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+		"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1",
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8",
+		"8/8/8/8/8/8/6k1/4K3 b - - 99 137",
+	}
+	for _, fen := range fens {
+		gp, er := ParseFEN(fen)
+		assert_er(er, t)
+		assert_equal(gp.String(), fen, t)
+	}
+
+	initial := MakeInitialGamePosition()
+	assert_equal(initial.String(), initialFEN, t)
+
+	gp, er := ParseFEN("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq -")
+	assert_er(er, t)
+	assert_equal(gp, initial, t)
+
+	for _, fen := range []string{
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq",
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 0",
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - -1 1",
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1 1",
+	} {
+		if _, er := ParseFEN(fen); er == nil {
+			t.Errorf("%s: expected error", fen)
+		}
+	}
+}
+
+func TestGamePositionFullMoves(t *testing.T) {
+	gp := MakeInitialGamePosition()
+	moves := []Move{makeTestMove("e2", "e4", NoPiece), makeTestMove("e7", "e5", NoPiece)}
+	var undos []MoveUndo
+	for i, move := range moves {
+		moves[i] = AddMoveFlags(move, &gp.Board, gp.State)
+		undos = append(undos, gp.MakeMove(moves[i]))
+	}
+	assert_equal(gp.String(), "rnbqkbnr/pppp1ppp/8/4p3/4P3/8/PPPP1PPP/RNBQKBNR w KQkq e6 0 2", t)
+	for i := len(moves) - 1; i >= 0; i-- {
+		gp.UnmakeMove(moves[i], undos[i])
+	}
+	assert_equal(gp, MakeInitialGamePosition(), t)
+}
+
+func TestParseFENErrors(t *testing.T) {
+	cases := []struct {
+		fen    string
+		field  FENField
+		offset int
+	}{
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq", FENFieldEnPassant, 50},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1 x", FENFieldFullMoveNumber, 57},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR", FENFieldSide, 43},
+		{"rnbqkbnr/ppppxppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", FENFieldPlacement, 13},
+		{"rnbqkbnr/ppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", FENFieldPlacement, 16},
+		{"rnbqkbnr/pppppppp/9/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", FENFieldPlacement, 18},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNRR w KQkq - 0 1", FENFieldPlacement, 43},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP w KQkq - 0 1", FENFieldPlacement, 34},
+		{"rnbqkbnr/pppppppp/8/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", FENFieldPlacement, 36},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR x KQkq - 0 1", FENFieldSide, 44},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkK - 0 1", FENFieldCastling, 49},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQxq - 0 1", FENFieldCastling, 48},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq e9 0 1", FENFieldEnPassant, 51},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - x 1", FENFieldHalfMoveClock, 53},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 0", FENFieldFullMoveNumber, 55},
+	}
+	for _, c := range cases {
+		_, er := ParseFEN(c.fen)
+		var fen_er *FENError
+		if !errors.As(er, &fen_er) {
+			t.Errorf("%s: expected FENError, got %v", c.fen, er)
+			continue
+		}
+		if fen_er.Field != c.field || fen_er.Offset != c.offset {
+			t.Errorf("%s: %s field at %d, expected %s field at %d", c.fen, fen_er.Field, fen_er.Offset, c.field, c.offset)
+		}
+	}
+
+	var piece_er *ErrorUnknownPieceLiteral
+	_, er := ParseFEN("rnbqkbnr/ppppxppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1")
+	if !errors.As(er, &piece_er) {
+		t.Error("underlying error is not available")
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+// result of the position for the side to move. A finished status ends the game by itself,
+// GameFiftyMoveDraw only allows a player to claim a draw (IsClaimable), the game is not
+// finished and a UI or match runner should keep playing until the draw is claimed
+type GameStatus uint8
+
+const (
+	GameOngoing GameStatus = iota
+	GameCheckmate
+	GameStalemate
+	GameFiftyMoveDraw // claimable only, the game goes on until a player claims it
+	GameInsufficientMaterial
+	GameSeventyFiveMoveDraw
+	GameFivefoldRepetition
+)
+
+var gameStatusNames = [...]string{
+	GameOngoing:              "ongoing",
+	GameCheckmate:            "checkmate",
+	GameStalemate:            "stalemate",
+	GameFiftyMoveDraw:        "fifty-move draw",
+	GameInsufficientMaterial: "insufficient material",
+	GameSeventyFiveMoveDraw:  "seventy-five-move draw",
+	GameFivefoldRepetition:   "fivefold repetition",
+}
+
+func (s GameStatus) String() string {
+	if int(s) < len(gameStatusNames) {
+		return gameStatusNames[s]
+	}
+	return "unknown"
+}
+
+func (s GameStatus) IsFinished() bool {
+	return s != GameOngoing && s != GameFiftyMoveDraw
+}
+
+// return true if a player may claim a draw, which the game does not end by itself
+func (s GameStatus) IsClaimable() bool {
+	return s == GameFiftyMoveDraw
+}
+
+func (s GameStatus) IsDraw() bool {
+	return s.IsFinished() && s != GameCheckmate
+}
+
+// squares of the pieces giving check to the side to move
+func Checkers(board *Board, bs BoardState) uint64 {
+	is_white := bs.Get_Turn()
+	king_pos, found := FindKing(board, is_white)
+	if !found {
+		return 0
+	}
+	return Attackers(king_pos, board, !is_white)
+}
+
+// return true if the king of the side to move is attacked
+func InCheck(board *Board, bs BoardState) bool {
+	is_white := bs.Get_Turn()
+	king_pos, found := FindKing(board, is_white)
+	return found && IsSquareAttacked(king_pos, board, !is_white)
+}
+
+// return true if neither side can checkmate: bare kings, a single minor piece,
+// or only bishops all standing on squares of the same color
+func IsInsufficientMaterial(board *Board) bool {
+	var minors int = 0
+	var bishop_colors [2]bool
+	var knights int = 0
+	for i, piece := range board {
+		switch piece.GetType() {
+		case NoPiece, King:
+		case Bishop:
+			minors++
+			pos := Position(i)
+			bishop_colors[(pos.GetRow()+pos.GetCol())&1] = true
+		case Knight:
+			minors++
+			knights++
+		default:
+			return false
+		}
+	}
+	if minors <= 1 {
+		return true
+	}
+	return knights == 0 && !(bishop_colors[0] && bishop_colors[1])
+}
+
+func GetGameStatus(board *Board, bs BoardState) GameStatus {
+	var buf [256]Move
+	if len(AppendLegalMoves(buf[:0], board, bs)) == 0 {
+		if InCheck(board, bs) {
+			return GameCheckmate
+		}
+		return GameStalemate
+	}
+	if IsInsufficientMaterial(board) {
+		return GameInsufficientMaterial
+	}
+	if bs.Get_HMoves() >= 100 {
+		return GameFiftyMoveDraw
+	}
+	return GameOngoing
+}
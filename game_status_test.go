@@ -0,0 +1,45 @@
+package main
+
+import "testing"
+
+func TestGameStatus(t *testing.T) {
+	cases := []struct {
+		fen      string
+		status   GameStatus
+		in_check bool
+		checkers uint64
+	}{
+		{initialFEN, GameOngoing, false, 0},
+		{"rnb1kbnr/pppp1ppp/8/4p3/6Pq/5P2/PPPPP2P/RNBQKBNR w KQkq - 1 3", GameCheckmate, true, squareSet("h4")},
+		{"7k/5Q2/6K1/8/8/8/8/8 b - - 0 1", GameStalemate, false, 0},
+		{"4k3/8/8/8/8/8/4r3/R3K3 w - - 100 80", GameFiftyMoveDraw, true, squareSet("e2")},
+		{"R3k3/8/4K3/8/8/8/8/8 b - - 100 80", GameCheckmate, true, squareSet("a8")},
+		{"4k3/8/8/8/8/8/3q4/3QK3 w - - 0 1", GameOngoing, true, squareSet("d2")},
+		{"4k3/8/4n3/8/8/8/8/4K3 w - - 0 1", GameInsufficientMaterial, false, 0},
+		{"4k3/8/8/8/8/8/8/4K3 w - - 0 1", GameInsufficientMaterial, false, 0},
+		{"4k3/8/8/8/8/8/8/4K3 w - - 100 80", GameInsufficientMaterial, false, 0},
+		{"2b1k3/8/8/8/8/8/8/4KB2 w - - 0 1", GameInsufficientMaterial, false, 0},
+		{"3bk3/8/8/8/8/8/8/4KB2 w - - 0 1", GameOngoing, false, 0},
+		{"4k3/8/4n3/8/8/8/8/4KN2 w - - 0 1", GameOngoing, false, 0},
+		{"4k3/2N1N3/8/8/8/8/8/4K3 b - - 0 1", GameOngoing, true, squareSet("c7")},
+	}
+	for _, c := range cases {
+		board, bs := makeTestPosition(c.fen, t)
+		if status := GetGameStatus(&board, bs); status != c.status {
+			t.Errorf("%s: status %s, expected %s", c.fen, status, c.status)
+		}
+		assert_equal(InCheck(&board, bs), c.in_check, t)
+		assert_equal(Checkers(&board, bs), c.checkers, t)
+	}
+}
+
+func TestGameStatusClaimable(t *testing.T) {
+	// the fifty-move rule only entitles a player to claim the draw
+	assert_equal(GameFiftyMoveDraw.IsFinished(), false, t)
+	assert_equal(GameFiftyMoveDraw.IsDraw(), false, t)
+	assert_equal(GameFiftyMoveDraw.IsClaimable(), true, t)
+	assert_equal(GameInsufficientMaterial.IsFinished(), true, t)
+	assert_equal(GameInsufficientMaterial.IsDraw(), true, t)
+	assert_equal(GameInsufficientMaterial.IsClaimable(), false, t)
+	assert_equal(GameStalemate.IsClaimable(), false, t)
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func playTestMoves(g *Game, t *testing.T, moves ...Move) {
+	for _, move := range moves {
+		if er := g.Play(move); er != nil {
+			t.Fatal(er.Error())
+		}
+	}
+}
+
+func TestGameUndoRedo(t *testing.T) {
+	g := NewGame()
+	e4 := makeTestMove("e2", "e4", NoPiece)
+	e5 := makeTestMove("e7", "e5", NoPiece)
+	nf3 := makeTestMove("g1", "f3", NoPiece)
+	playTestMoves(g, t, e4, e5, nf3)
+
+	var illegal *ErrorIllegalMove
+	if er := g.Play(e4); !errors.As(er, &illegal) {
+		t.Error("illegal move accepted")
+	}
+
+	assert_equal(g.Undo(), true, t)
+	assert_equal(g.Undo(), true, t)
+	assert_equal(g.Position.String(), "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1", t)
+	assert_equal(len(g.Moves()), 1, t)
+
+	// playing the undone move keeps the redo list
+	playTestMoves(g, t, e5)
+	assert_equal(g.Redo(), true, t)
+	assert_equal(g.Redo(), false, t)
+	assert_equal(g.Position.String(), "rnbqkbnr/pppp1ppp/8/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R b KQkq - 1 2", t)
+
+	for g.Undo() {
+	}
+	assert_equal(g.Position, MakeInitialGamePosition(), t)
+	assert_equal(len(g.Moves()), 0, t)
+
+	// playing another move clears the redo list
+	playTestMoves(g, t, makeTestMove("d2", "d4", NoPiece))
+	assert_equal(g.Redo(), false, t)
+}
+
+func TestGameRepetition(t *testing.T) {
+	g := NewGame()
+	shuffle := []Move{
+		makeTestMove("g1", "f3", NoPiece),
+		makeTestMove("g8", "f6", NoPiece),
+		makeTestMove("f3", "g1", NoPiece),
+		makeTestMove("f6", "g8", NoPiece),
+	}
+	assert_equal(g.RepetitionCount(), 1, t)
+	playTestMoves(g, t, shuffle...)
+	assert_equal(g.RepetitionCount(), 2, t)
+	assert_equal(g.IsThreefoldRepetition(), false, t)
+	playTestMoves(g, t, shuffle...)
+	assert_equal(g.IsThreefoldRepetition(), true, t)
+	assert_equal(g.Status(), GameOngoing, t)
+	playTestMoves(g, t, shuffle...)
+	playTestMoves(g, t, shuffle...)
+	assert_equal(g.IsFivefoldRepetition(), true, t)
+	assert_equal(g.Status(), GameFivefoldRepetition, t)
+	g.Undo()
+	assert_equal(g.IsFivefoldRepetition(), false, t)
+}
+
+func TestGameRepetitionEnPassant(t *testing.T) {
+	// en passant right differs only if the capture is possible
+	gp, er := ParseFEN("4k3/8/8/8/3p4/8/4P3/4K1N1 w - - 0 1")
+	assert_er(er, t)
+	g := MakeGame(gp)
+	playTestMoves(g, t,
+		makeTestMove("e2", "e4", NoPiece),
+		makeTestMove("e8", "d7", NoPiece),
+		makeTestMove("g1", "f3", NoPiece),
+		makeTestMove("d7", "e8", NoPiece),
+		makeTestMove("f3", "g1", NoPiece),
+	)
+	assert_equal(g.RepetitionCount(), 1, t)
+
+	gp, er = ParseFEN("4k3/8/8/8/8/8/4P3/4K1N1 w - - 0 1")
+	assert_er(er, t)
+	g = MakeGame(gp)
+	playTestMoves(g, t,
+		makeTestMove("e2", "e4", NoPiece),
+		makeTestMove("e8", "d7", NoPiece),
+		makeTestMove("g1", "f3", NoPiece),
+		makeTestMove("d7", "e8", NoPiece),
+		makeTestMove("f3", "g1", NoPiece),
+	)
+	assert_equal(g.RepetitionCount(), 2, t)
+}
+
+func TestGameSeventyFiveMoveRule(t *testing.T) {
+	gp, er := ParseFEN("4k3/8/8/8/8/8/8/R3K3 w - - 149 100")
+	assert_er(er, t)
+	g := MakeGame(gp)
+	assert_equal(g.Status(), GameFiftyMoveDraw, t)
+	playTestMoves(g, t, makeTestMove("a1", "a2", NoPiece))
+	assert_equal(g.IsSeventyFiveMoveRule(), true, t)
+	assert_equal(g.Status(), GameSeventyFiveMoveDraw, t)
+}
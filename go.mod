@@ -2,4 +2,4 @@ module enginsant
 
 go 1.24.3
 
-require golang.org/x/exp v0.0.0-20250506013437-ce4c2cf36ca6 // indirect
+require golang.org/x/exp v0.0.0-20250506013437-ce4c2cf36ca6
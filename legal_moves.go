@@ -0,0 +1,175 @@
+package main
+
+// signature shared by NextXXXMove functions of the regular piece moves
+type NextMoveFunc func(pos Position, is_white bool, id uint, board *Board) (move Move, next_id uint, is_finished bool)
+
+func FindKing(board *Board, is_white bool) (pos Position, found bool) {
+	king := King
+	if is_white {
+		king = W_King
+	}
+	for i, piece := range board {
+		if piece == king {
+			return Position(i), true
+		}
+	}
+	return Position(0), false
+}
+
+func appendCursorMoves(moves []Move, pos Position, is_white bool, board *Board, next NextMoveFunc) []Move {
+	var id uint = 0
+	var finished = false
+	var move Move
+	for {
+		move, id, finished = next(pos, is_white, id, board)
+		if finished {
+			return moves
+		}
+		moves = append(moves, move)
+	}
+}
+
+func appendPawnMoves(moves []Move, pos Position, is_white bool, board *Board) []Move {
+	var last_row int8 = 0
+	if is_white {
+		last_row = 7
+	}
+	var id uint = 0
+	var finished = false
+	var move Move
+	for {
+		move, id, finished = NextPawnMove(pos, is_white, id, board)
+		if finished {
+			return moves
+		}
+		if move.GetEnd().GetRow() != last_row {
+			moves = append(moves, move)
+			continue
+		}
+		var promote_id uint = 0
+		var promote Piece
+		for {
+			promote, promote_id, finished = NextPromotionMove(promote_id, is_white)
+			if finished {
+				break
+			}
+			moves = append(moves, move.SetPromote(promote))
+		}
+	}
+}
+
+func appendEnPassantMoves(moves []Move, board *Board, bs BoardState) []Move {
+	if !bs.Get_IsEnPos() {
+		return moves
+	}
+	var id uint = 0
+	var finished = false
+	var move Move
+	for {
+		move, id, finished = NextEnPassantMove(bs.Get_EnPos(), id, board, bs.Get_Turn())
+		if finished {
+			return moves
+		}
+		moves = append(moves, move)
+	}
+}
+
+func appendCastleMoves(moves []Move, board *Board, bs BoardState) []Move {
+	var r int8 = 7
+	if bs.Get_Turn() {
+		r = 0
+	}
+	var id uint = 0
+	var finished = false
+	var castle_type CastleType
+	for {
+		castle_type, id, finished = NextCastleMove(id, board, bs)
+		if finished {
+			return moves
+		}
+		var move Move
+		move = move.SetStart(MakePos(r, 4))
+		switch castle_type {
+		case CastleType_King:
+			move = move.SetEnd(MakePos(r, 6))
+		case CastleType_Queen:
+			move = move.SetEnd(MakePos(r, 2))
+		}
+		moves = append(moves, move)
+	}
+}
+
+// appends moves which follow piece movement rules, but can leave own king in check
+func AppendPseudoLegalMoves(moves []Move, board *Board, bs BoardState) []Move {
+	first := len(moves)
+	is_white := bs.Get_Turn()
+	for i, piece := range board {
+		if piece == NoPiece || piece.IsWhite() != is_white {
+			continue
+		}
+		pos := Position(i)
+		switch piece.GetType() {
+		case Pawn:
+			moves = appendPawnMoves(moves, pos, is_white, board)
+		case Knight:
+			moves = appendCursorMoves(moves, pos, is_white, board, NextKnightMove)
+		case Bishop:
+			moves = appendCursorMoves(moves, pos, is_white, board, NextBishopMove)
+		case Rook:
+			moves = appendCursorMoves(moves, pos, is_white, board, NextRookMove)
+		case Queen:
+			moves = appendCursorMoves(moves, pos, is_white, board, NextQueenMove)
+		case King:
+			moves = appendCursorMoves(moves, pos, is_white, board, NextKingMove)
+		}
+	}
+	moves = appendEnPassantMoves(moves, board, bs)
+	moves = appendCastleMoves(moves, board, bs)
+	for i := first; i < len(moves); i++ {
+		moves[i] = AddMoveFlags(moves[i], board, bs)
+	}
+	return moves
+}
+
+// return true if after the move own king is not in check
+func IsMoveSafe(move Move, board *Board, bs BoardState) bool {
+	is_white := bs.Get_Turn()
+	undo := MakeMove(move, board, &bs)
+	king_pos, found := FindKing(board, is_white)
+	is_safe := !found || !IsSquareAttacked(king_pos, board, !is_white)
+	UnmakeMove(move, board, &bs, undo)
+	return is_safe
+}
+
+// appends all legal moves of the side to move to moves and returns the extended slice
+func AppendLegalMoves(moves []Move, board *Board, bs BoardState) []Move {
+	first := len(moves)
+	moves = AppendPseudoLegalMoves(moves, board, bs)
+	n := first
+	for _, move := range moves[first:] {
+		if IsMoveSafe(move, board, bs) {
+			moves[n] = move
+			n++
+		}
+	}
+	return moves[:n]
+}
+
+// finds the move with the same start, end and promotion, flags are ignored
+func findMove(moves []Move, move CompactMove) (Move, bool) {
+	for _, m := range moves {
+		if m.Compact() == move {
+			return m, true
+		}
+	}
+	return 0, false
+}
+
+func containsMove(moves []Move, move Move) bool {
+	_, found := findMove(moves, move.Compact())
+	return found
+}
+
+func GenerateLegalMoves(board *Board, bs BoardState) []Move {
+	return AppendLegalMoves(make([]Move, 0, 64), board, bs)
+}
@@ -0,0 +1,54 @@
+package main
+
+import "testing"
+
+func makeTestPosition(fen string, t *testing.T) (Board, BoardState) {
+	gp, er := ParseFEN(fen)
+	assert_er(er, t)
+	return gp.Board, gp.State
+}
+
+func TestGenerateLegalMovesCount(t *testing.T) {
+	cases := []struct {
+		fen   string
+		count int
+	}{
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", 20},
+		{"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1", 48},
+		{"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1", 14},
+		{"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1", 6},
+		{"rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 0 1", 44},
+		{"7k/8/8/8/8/8/5PPP/r5K1 w - - 0 1", 0}, // mate
+	}
+	for _, c := range cases {
+		board, bs := makeTestPosition(c.fen, t)
+		moves := GenerateLegalMoves(&board, bs)
+		if len(moves) != c.count {
+			t.Errorf("%s: %d moves, expected %d", c.fen, len(moves), c.count)
+		}
+	}
+}
+
+func TestGenerateLegalMovesSpecial(t *testing.T) {
+	// castle through attacked f1 is not allowed, queen side is
+	board, bs := makeTestPosition("4k3/8/8/8/8/8/5r2/R3K2R w KQ - 0 1", t)
+	moves := GenerateLegalMoves(&board, bs)
+	if containsMove(moves, makeTestMove("e1", "g1", NoPiece)) {
+		t.Error("castle through check generated")
+	}
+	if !containsMove(moves, makeTestMove("e1", "c1", NoPiece)) {
+		t.Error("queen side castle not generated")
+	}
+
+	// en passant and all promotions
+	board, bs = makeTestPosition("4k3/1P6/8/3pP3/8/8/8/4K3 w - d6 0 1", t)
+	moves = GenerateLegalMoves(&board, bs)
+	if !containsMove(moves, makeTestMove("e5", "d6", NoPiece)) {
+		t.Error("en passant not generated")
+	}
+	for _, p := range [...]Piece{Queen, Rook, Bishop, Knight} {
+		if !containsMove(moves, makeTestMove("b7", "b8", p)) {
+			t.Errorf("promotion to %v not generated", p)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+//go:generate go run . genmagic magic_numbers.go
+
+/*
+magic bitboards for the sliding pieces:
+attacks of a rook or a bishop on a square depend only on the occupancy of the relevant squares (mask),
+multiplication by the magic number maps every subset of the mask to a unique index of the attack table.
+Magic numbers are generated by "go generate" into magic_numbers.go, at init they are searched again
+with a fixed seed only if the stored numbers do not fit.
+*/
+
+type magicEntry struct {
+	mask    Bitboard
+	magic   uint64
+	shift   uint8
+	attacks []Bitboard
+}
+
+func (m *magicEntry) index(occupied Bitboard) uint64 {
+	return (uint64(occupied&m.mask) * m.magic) >> m.shift
+}
+
+var (
+	rookMagics   [BoardSize * BoardSize]magicEntry
+	bishopMagics [BoardSize * BoardSize]magicEntry
+)
+
+// attacks of a slider on pos walking the directions until the first occupied square
+func slidingAttacks(pos Position, occupied Bitboard, directions []MoveStencil) Bitboard {
+	var res Bitboard
+	for _, dir := range directions {
+		r, c := applyStencil(pos, dir)
+		for CheckBoardPos(r, c) {
+			res |= SquareBB(MakePos(r, c))
+			if occupied.Has(MakePos(r, c)) {
+				break
+			}
+			r += dir[0]
+			c += dir[1]
+		}
+	}
+	return res
+}
+
+// squares whose occupancy changes the attacks, the last square of every direction does not matter
+func relevantOccupancyMask(pos Position, directions []MoveStencil) Bitboard {
+	var res Bitboard
+	for _, dir := range directions {
+		r, c := applyStencil(pos, dir)
+		for CheckBoardPos(r+dir[0], c+dir[1]) {
+			res |= SquareBB(MakePos(r, c))
+			r += dir[0]
+			c += dir[1]
+		}
+	}
+	return res
+}
+
+// random number with few set bits, such numbers are more likely to be magic
+func nextSparseKey(state *uint64) uint64 {
+	return nextZobristKey(state) & nextZobristKey(state) & nextZobristKey(state)
+}
+
+// all subsets of the mask with the attacks for them
+func magicOccupancies(pos Position, mask Bitboard, directions []MoveStencil) (occupancies []Bitboard, references []Bitboard) {
+	size := 1 << mask.Count()
+	occupancies = make([]Bitboard, 0, size)
+	references = make([]Bitboard, 0, size)
+	var occ Bitboard = 0
+	for {
+		occupancies = append(occupancies, occ)
+		references = append(references, slidingAttacks(pos, occ, directions))
+		occ = (occ - mask) & mask
+		if occ == 0 {
+			return occupancies, references
+		}
+	}
+}
+
+// fills attack table of the entry, return false if the magic maps different attacks to the same index
+func (m *magicEntry) fill(occupancies []Bitboard, references []Bitboard, used []bool) bool {
+	clear(used)
+	for i, occ := range occupancies {
+		idx := m.index(occ)
+		if used[idx] && m.attacks[idx] != references[i] {
+			return false
+		}
+		used[idx] = true
+		m.attacks[idx] = references[i]
+	}
+	return true
+}
+
+// entry for the given magic number, a new magic number is searched if the given one is not magic
+func makeMagicEntry(pos Position, directions []MoveStencil, magic uint64, state *uint64) magicEntry {
+	mask := relevantOccupancyMask(pos, directions)
+	n := mask.Count()
+	occupancies, references := magicOccupancies(pos, mask, directions)
+	entry := magicEntry{mask: mask, magic: magic, shift: uint8(64 - n), attacks: make([]Bitboard, 1<<n)}
+	used := make([]bool, 1<<n)
+	if magic != 0 && entry.fill(occupancies, references, used) {
+		return entry
+	}
+	for {
+		entry.magic = nextSparseKey(state)
+		if Bitboard((uint64(mask)*entry.magic)&0xff00000000000000).Count() < 6 {
+			continue
+		}
+		if entry.fill(occupancies, references, used) {
+			return entry
+		}
+	}
+}
+
+func init() {
+	state := zobristSeed
+	for i := range rookMagics {
+		rookMagics[i] = makeMagicEntry(Position(i), rookDirections[:], rookMagicNumbers[i], &state)
+		bishopMagics[i] = makeMagicEntry(Position(i), bishopDirections[:], bishopMagicNumbers[i], &state)
+	}
+}
+
+// searches magic numbers from scratch and writes them as Go source
+func WriteMagicNumbers(w io.Writer) error {
+	state := zobristSeed
+	var rooks, bishops [BoardSize * BoardSize]uint64
+	for i := range rooks {
+		rooks[i] = makeMagicEntry(Position(i), rookDirections[:], 0, &state).magic
+		bishops[i] = makeMagicEntry(Position(i), bishopDirections[:], 0, &state).magic
+	}
+	var sb strings.Builder
+	sb.WriteString("// Code generated by \"enginsant genmagic\"; DO NOT EDIT.\n\npackage main\n")
+	for _, table := range []struct {
+		name    string
+		numbers []uint64
+	}{{"rookMagicNumbers", rooks[:]}, {"bishopMagicNumbers", bishops[:]}} {
+		fmt.Fprintf(&sb, "\nvar %s = [BoardSize * BoardSize]uint64{\n", table.name)
+		for _, n := range table.numbers {
+			fmt.Fprintf(&sb, "\t0x%016x,\n", n)
+		}
+		sb.WriteString("}\n")
+	}
+	_, er := io.WriteString(w, sb.String())
+	return er
+}
+
+func RookAttacks(pos Position, occupied Bitboard) Bitboard {
+	m := &rookMagics[pos]
+	return m.attacks[m.index(occupied)]
+}
+
+func BishopAttacks(pos Position, occupied Bitboard) Bitboard {
+	m := &bishopMagics[pos]
+	return m.attacks[m.index(occupied)]
+}
+
+func QueenAttacks(pos Position, occupied Bitboard) Bitboard {
+	return RookAttacks(pos, occupied) | BishopAttacks(pos, occupied)
+}
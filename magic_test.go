@@ -0,0 +1,53 @@
+package main
+
+import "testing"
+
+// attacks from the stencil walk of NextXXXMove, every occupied square holds an enemy piece
+func stencilAttacks(pos Position, occupied Bitboard, next NextMoveFunc) Bitboard {
+	board := MakeEmptyBoard()
+	for set := occupied &^ SquareBB(pos); set != 0; {
+		board.SetPiece(set.PopLSB(), B_Pawn)
+	}
+	board.SetPiece(pos, W_Queen)
+	var res Bitboard
+	var id uint = 0
+	var finished = false
+	var move Move
+	for {
+		move, id, finished = next(pos, true, id, &board)
+		if finished {
+			return res
+		}
+		res |= SquareBB(move.GetEnd())
+	}
+}
+
+func TestMagicAttacks(t *testing.T) {
+	state := uint64(12345)
+	for i := 0; i < int(BoardSize*BoardSize); i++ {
+		pos := Position(i)
+		occupancies := []Bitboard{0, ^Bitboard(0)}
+		for j := 0; j < 300; j++ {
+			occupancies = append(occupancies, Bitboard(nextZobristKey(&state)), Bitboard(nextSparseKey(&state)))
+		}
+		for _, occ := range occupancies {
+			if a, e := RookAttacks(pos, occ), stencilAttacks(pos, occ, NextRookMove); a != e {
+				t.Fatalf("rook on %v with occupancy\n%v\nattacks\n%v\nexpected\n%v", pos, occ, a, e)
+			}
+			if a, e := BishopAttacks(pos, occ), stencilAttacks(pos, occ, NextBishopMove); a != e {
+				t.Fatalf("bishop on %v with occupancy\n%v\nattacks\n%v\nexpected\n%v", pos, occ, a, e)
+			}
+			if a, e := QueenAttacks(pos, occ), stencilAttacks(pos, occ, NextQueenMove); a != e {
+				t.Fatalf("queen on %v with occupancy\n%v\nattacks\n%v\nexpected\n%v", pos, occ, a, e)
+			}
+		}
+	}
+}
+
+func TestMagicNumbersAreMagic(t *testing.T) {
+	for i := range rookMagics {
+		if rookMagics[i].magic != rookMagicNumbers[i] || bishopMagics[i].magic != bishopMagicNumbers[i] {
+			t.Fatalf("stored magic number for %v does not fit, run go generate", Position(i))
+		}
+	}
+}
@@ -1,39 +1,128 @@
 package main
 
 import (
+	"bufio"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
 )
 
+const initialFEN = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+
 func main() {
-	pcs := [...]Piece{
-		W_Bishop,
-		W_King,
-		B_Bishop,
-		B_King,
-	}
-	poses := [...]Position{
-		MakePos(0, 0),
-		MakePos(0, 1),
-		MakePos(0, 2),
-		MakePos(6, 0),
-	}
-	board := MakeEmptyBoard()
-	for i := 0; i < len(pcs); i++ {
-		board.SetPiece(poses[i], pcs[i])
-	}
-	fen, er := board.FEN()
-	if er != nil {
-		fmt.Println(er.Error())
+	if len(os.Args) > 1 {
+		var er error
+		switch os.Args[1] {
+		case "perft":
+			er = runPerft(os.Args[2:])
+		case "genmagic":
+			er = runGenMagic(os.Args[2:])
+		default:
+			er = fmt.Errorf("unknown command: %s\nusage: enginsant [perft|genmagic] [arguments]", os.Args[1])
+		}
+		if er != nil {
+			fmt.Fprintln(os.Stderr, er.Error())
+			os.Exit(1)
+		}
 		return
 	}
 
-	b, er := MakeBoardFromFEN(fen)
+	if er := runProtocol(os.Stdin, os.Stdout); er != nil {
+		fmt.Fprintln(os.Stderr, er.Error())
+		os.Exit(1)
+	}
+}
+
+// runs UCI or, if the first command is xboard, CECP session until quit or the end of input
+func runProtocol(in io.Reader, out io.Writer) error {
+	var engine interface{ Handle(line string) bool }
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if engine == nil {
+			tokens := strings.Fields(line)
+			switch {
+			case len(tokens) == 0:
+				continue
+			case tokens[0] == "xboard":
+				engine = MakeCECPEngine(out)
+			default:
+				engine = MakeUCIEngine(out)
+			}
+		}
+		if engine.Handle(line) {
+			return nil
+		}
+	}
+	if engine != nil {
+		engine.Handle("quit")
+	}
+	return scanner.Err()
+}
+
+// perft [-divide] [-stats] [-bb] <depth> [FEN]
+func runPerft(args []string) error {
+	flags := flag.NewFlagSet("perft", flag.ContinueOnError)
+	divide := flags.Bool("divide", false, "print node count for every root move")
+	stats := flags.Bool("stats", false, "print captures, en passants, castles, promotions, checks and mates")
+	use_bb := flags.Bool("bb", false, "count nodes with the bitboard move generator")
+	if er := flags.Parse(args); er != nil {
+		return er
+	}
+	if flags.NArg() < 1 {
+		return errors.New("usage: perft [-divide] [-stats] [-bb] <depth> [FEN]")
+	}
+	depth, er := strconv.Atoi(flags.Arg(0))
+	if er != nil || depth < 0 {
+		return fmt.Errorf("incorrect depth: %s", flags.Arg(0))
+	}
+	fen := initialFEN
+	if flags.NArg() > 1 {
+		fen = strings.Join(flags.Args()[1:], " ")
+	}
+	gp, er := ParseFEN(fen)
 	if er != nil {
-		fmt.Println(er.Error())
+		return er
+	}
+	board := gp.Board
+	bs := gp.State
+
+	switch {
+	case *divide:
+		var total uint64 = 0
+		for _, entry := range Divide(&board, bs, depth) {
+			fmt.Printf("%s: %d\n", entry.Move, entry.Nodes)
+			total += entry.Nodes
+		}
+		fmt.Printf("\nNodes searched: %d\n", total)
+	case *stats:
+		s := PerftDetailed(&board, bs, depth)
+		fmt.Printf("Nodes: %d\nCaptures: %d\nE.p.: %d\nCastles: %d\nPromotions: %d\nChecks: %d\nCheckmates: %d\n",
+			s.Nodes, s.Captures, s.EnPassants, s.Castles, s.Promotions, s.Checks, s.Mates)
+	case *use_bb:
+		fmt.Println(PerftBB(&gp, depth))
+	default:
+		fmt.Println(Perft(&board, bs, depth))
 	}
-	b.FEN()
+	return nil
+}
 
-	bs := MakeInitialBoardState()
-	fen += " " + bs.FEN()
-	fmt.Println(fen)
+// genmagic <output file>
+func runGenMagic(args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: genmagic <output file>")
+	}
+	f, er := os.Create(args[0])
+	if er != nil {
+		return er
+	}
+	if er := WriteMagicNumbers(f); er != nil {
+		f.Close()
+		return er
+	}
+	return f.Close()
 }
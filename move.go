@@ -1,15 +1,30 @@
 package main
 
-type Move uint16
+// the low 16 bits are the compact form of the move (CompactMove), the flags are above them
+type Move uint32
+
+// start, end and promotion of the move without flags, used by opening books and hash tables
+type CompactMove uint16
 
 const (
-	movePromoteMask = 0b0111_000000_000000 // piece to which the pawn is promoted to
+	movePromoteMask = 0b0111_000000_000000 // type of the piece to which the pawn is promoted to
 	moveStartMask   = 0b0000_111111_000000
 	moveEndMask     = 0b0000_000000_111111
+	moveCompactMask = 0xffff
+)
+
+const (
+	MoveFlagCapture    Move = 1 << (16 + iota) // including en passant
+	MoveFlagDoublePush                         // pawn move forward 2
+	MoveFlagEnPassant
+	MoveFlagKingCastle
+	MoveFlagQueenCastle
+
+	moveFlagsMask = MoveFlagCapture | MoveFlagDoublePush | MoveFlagEnPassant | MoveFlagKingCastle | MoveFlagQueenCastle
 )
 
 func (m Move) SetPromote(p Piece) Move {
-	return (m &^ movePromoteMask) | (Move(p) << 12)
+	return (m &^ movePromoteMask) | (Move(p.GetType()) << 12)
 }
 func (m Move) GetPromote() Piece {
 	return Piece((m & movePromoteMask) >> 12)
@@ -29,3 +44,91 @@ func (m Move) SetEnd(p Position) Move {
 func (m Move) GetEnd() Position {
 	return Position(m & moveEndMask)
 }
+
+func (m Move) String() string {
+	return m.UCI()
+}
+
+func (m Move) SetFlags(flags Move) Move {
+	return (m &^ moveFlagsMask) | (flags & moveFlagsMask)
+}
+
+func (m Move) GetFlags() Move {
+	return m & moveFlagsMask
+}
+
+func (m Move) IsCapture() bool {
+	return m&MoveFlagCapture != 0
+}
+
+func (m Move) IsDoublePush() bool {
+	return m&MoveFlagDoublePush != 0
+}
+
+func (m Move) IsEnPassant() bool {
+	return m&MoveFlagEnPassant != 0
+}
+
+func (m Move) IsCastle() bool {
+	return m&(MoveFlagKingCastle|MoveFlagQueenCastle) != 0
+}
+
+func (m Move) IsKingCastle() bool {
+	return m&MoveFlagKingCastle != 0
+}
+
+func (m Move) IsQueenCastle() bool {
+	return m&MoveFlagQueenCastle != 0
+}
+
+func (m Move) IsPromotion() bool {
+	return m&movePromoteMask != 0
+}
+
+func (m Move) Compact() CompactMove {
+	return CompactMove(m & moveCompactMask)
+}
+
+// move without flags, use AddMoveFlags to restore them
+func (cm CompactMove) Move() Move {
+	return Move(cm)
+}
+
+func (cm CompactMove) String() string {
+	return cm.Move().String()
+}
+
+// sets flags of the move from the position in which it is made, the move should be possible
+func AddMoveFlags(move Move, board *Board, bs BoardState) Move {
+	start := move.GetStart()
+	end := move.GetEnd()
+	piece := board.GetPiece(start)
+	var flags Move
+	if board.GetPiece(end) != NoPiece {
+		flags |= MoveFlagCapture
+	}
+	switch piece.GetType() {
+	case Pawn:
+		switch {
+		case Abs(end.GetRow()-start.GetRow()) == 2:
+			flags |= MoveFlagDoublePush
+		case bs.Get_IsEnPos() && end == bs.Get_EnPos() && start.GetCol() != end.GetCol():
+			flags |= MoveFlagCapture | MoveFlagEnPassant
+		}
+	case King:
+		if start.GetCol() == 4 {
+			switch end.GetCol() - start.GetCol() {
+			case 2:
+				flags |= MoveFlagKingCastle
+			case -2:
+				flags |= MoveFlagQueenCastle
+			}
+		}
+	}
+	return move.SetFlags(flags)
+}
+
+// position in which the move is made is required to restore flags
+func ExpandMove(cm CompactMove, board *Board, bs BoardState) Move {
+	return AddMoveFlags(cm.Move(), board, bs)
+}
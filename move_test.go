@@ -0,0 +1,32 @@
+package main
+
+import "testing"
+
+func TestMoveFlags(t *testing.T) {
+	board, bs := makeTestPosition("r3k2r/1P6/8/3pP3/8/8/4P3/R3K2R w KQkq d6 0 1", t)
+	cases := []struct {
+		move  Move
+		flags Move
+	}{
+		{makeTestMove("e2", "e4", NoPiece), MoveFlagDoublePush},
+		{makeTestMove("e2", "e3", NoPiece), 0},
+		{makeTestMove("e5", "d6", NoPiece), MoveFlagCapture | MoveFlagEnPassant},
+		{makeTestMove("e1", "g1", NoPiece), MoveFlagKingCastle},
+		{makeTestMove("e1", "c1", NoPiece), MoveFlagQueenCastle},
+		{makeTestMove("b7", "a8", Queen), MoveFlagCapture},
+		{makeTestMove("b7", "b8", Knight), 0},
+		{makeTestMove("a1", "a8", NoPiece), MoveFlagCapture},
+	}
+	for _, c := range cases {
+		move := AddMoveFlags(c.move, &board, bs)
+		if move.GetFlags() != c.flags {
+			t.Errorf("%v: flags %x, expected %x", c.move, move.GetFlags(), c.flags)
+		}
+		assert_equal(move.Compact(), c.move.Compact(), t)
+		assert_equal(ExpandMove(move.Compact(), &board, bs), move, t)
+		assert_equal(move.GetStart(), c.move.GetStart(), t)
+		assert_equal(move.GetEnd(), c.move.GetEnd(), t)
+		assert_equal(move.GetPromote(), c.move.GetPromote(), t)
+		assert_equal(move.IsPromotion(), c.move.GetPromote() != NoPiece, t)
+	}
+}
@@ -194,8 +194,8 @@ func NextSlidingPieceMove(pos Position, is_white bool, id uint, board *Board, st
 		} else {
 			id++
 		}
-		res.SetStart(pos)
-		res.SetEnd(end_pos)
+		res = res.SetStart(pos)
+		res = res.SetEnd(end_pos)
 		return res, id, false
 	}
 	return res, id, true
@@ -233,8 +233,8 @@ var KnightStencils = [...]MoveStencil{
 func NextKnightMove(pos Position, is_white bool, id uint, board *Board) (move Move, next_id uint, is_finished bool) {
 	var res Move
 	for id < uint(len(KnightStencils)) {
-		id++
 		stencil := KnightStencils[id]
+		id++
 		re, ce := applyStencil(pos, stencil)
 		if !CheckBoardPos(re, ce) {
 			continue
@@ -243,8 +243,8 @@ func NextKnightMove(pos Position, is_white bool, id uint, board *Board) (move Mo
 		if isTakenByFriend(board, end_pos, is_white) {
 			continue
 		}
-		res.SetStart(pos)
-		res.SetEnd(end_pos)
+		res = res.SetStart(pos)
+		res = res.SetEnd(end_pos)
 		return res, id, false
 	}
 
@@ -266,8 +266,8 @@ var KingStencils = [...]MoveStencil{
 func NextKingMove(pos Position, is_white bool, id uint, board *Board) (move Move, next_id uint, is_finished bool) {
 	var res Move
 	for id < uint(len(KingStencils)) {
-		id++
 		stencil := KingStencils[id]
+		id++
 		re, ce := applyStencil(pos, stencil)
 		if !CheckBoardPos(re, ce) {
 			continue
@@ -276,8 +276,8 @@ func NextKingMove(pos Position, is_white bool, id uint, board *Board) (move Move
 		if isTakenByFriend(board, end_pos, is_white) {
 			continue
 		}
-		res.SetStart(pos)
-		res.SetEnd(end_pos)
+		res = res.SetStart(pos)
+		res = res.SetEnd(end_pos)
 		return res, id, false
 	}
 
@@ -286,12 +286,14 @@ func NextKingMove(pos Position, is_white bool, id uint, board *Board) (move Move
 
 func NextPawnMove(pos Position, is_white bool, id uint, board *Board) (move Move, next_id uint, is_finished bool) {
 	var res Move
-	res.SetStart(pos)
 	var direction int8
+	var start_row int8
 	if is_white {
 		direction = 1
+		start_row = 1
 	} else {
 		direction = -1
+		start_row = 6
 	}
 	for id < 4 {
 		id_cur := id
@@ -304,6 +306,9 @@ func NextPawnMove(pos Position, is_white bool, id uint, board *Board) (move Move
 		case 0: // move by 1
 			re += direction
 		case 1: // move by 2
+			if re != start_row || board.GetPiece(MakePos(re+direction, ce)) != NoPiece {
+				continue
+			}
 			re += direction * 2
 		case 2: // capture left
 			ce--
@@ -333,8 +338,8 @@ func NextPawnMove(pos Position, is_white bool, id uint, board *Board) (move Move
 			}
 		}
 
-		res.SetStart(pos)
-		res.SetEnd(end_pos)
+		res = res.SetStart(pos)
+		res = res.SetEnd(end_pos)
 		return res, id, false
 	}
 	return res, id, true
@@ -386,85 +391,13 @@ func NextEnPassantMove(enPos Position, id uint, board *Board, is_white bool) (mo
 			continue
 		}
 
-		res.SetStart(start_pos)
-		res.SetEnd(enPos)
+		res = res.SetStart(start_pos)
+		res = res.SetEnd(enPos)
 		return res, id, false
 	}
 	return res, id, true
 }
 
-func IsPositionUnderAttack(pos Position, board *Board, is_white bool) bool {
-	{ // bishop, rook, queen
-		var id uint = 0
-		var finished = false
-		var move Move
-		for {
-			move, id, finished = NextQueenMove(pos, !is_white, id, board)
-			if finished {
-				break
-			}
-			end_pos := move.GetEnd()
-			ep := board.GetPiece(end_pos)
-			if (is_white && (ep == B_Bishop || ep == B_Rook || ep == B_Queen)) ||
-				(!is_white && (ep == W_Bishop || ep == W_Rook || ep == W_Queen)) {
-				return true
-			}
-		}
-	}
-	{ // knight
-		var id uint = 0
-		var finished = false
-		var move Move
-		for {
-			move, id, finished = NextKnightMove(pos, !is_white, id, board)
-			if finished {
-				break
-			}
-			end_pos := move.GetEnd()
-			ep := board.GetPiece(end_pos)
-			if (is_white && ep == B_Knight) ||
-				(!is_white && ep == W_Knight) {
-				return true
-			}
-		}
-	}
-	{ // pawn
-		var id uint = 2 // check only captures
-		var finished = false
-		var move Move
-		for {
-			move, id, finished = NextPawnMove(pos, is_white, id, board)
-			if finished {
-				break
-			}
-			end_pos := move.GetEnd()
-			ep := board.GetPiece(end_pos)
-			if (is_white && ep == B_Pawn) ||
-				(!is_white && ep == W_Pawn) {
-				return true
-			}
-		}
-	}
-	{ // king
-		var id uint = 0
-		var finished = false
-		var move Move
-		for {
-			move, id, finished = NextKingMove(pos, !is_white, id, board)
-			if finished {
-				break
-			}
-			end_pos := move.GetEnd()
-			ep := board.GetPiece(end_pos)
-			if (is_white && ep == B_King) ||
-				(!is_white && ep == W_King) {
-				return true
-			}
-		}
-	}
-	return false
-}
-
 type CastleType uint8
 
 const (
@@ -479,10 +412,16 @@ castle types : 0 = king, 1 = queen
 func NextCastleMove(id uint, board *Board, bs BoardState) (castle_type CastleType, next_id uint, is_finished bool) {
 	is_white := bs.Get_Turn()
 	var r int8
+	var king Piece
 	if is_white {
 		r = 0
+		king = W_King
 	} else {
 		r = 7
+		king = B_King
+	}
+	if board.GetPiece(MakePos(r, 4)) != king {
+		return 0, 2, true
 	}
 	for id < 2 {
 		id_cur := id
@@ -498,11 +437,14 @@ func NextCastleMove(id uint, board *Board, bs BoardState) (castle_type CastleTyp
 				board.GetPiece(p_g).GetType() != NoPiece {
 				continue
 			}
-			if IsPosUnderAttack(MakePos(r, 4), board, is_white) { // is king in check
+			if board.GetPiece(MakePos(r, 7)) != Rook|(king&White) {
 				continue
 			}
-			if IsPositionUnderAttack(p_f, board, is_white) || // is passing squares in check
-				IsPositionUnderAttack(p_g, board, is_white) {
+			if IsSquareAttacked(MakePos(r, 4), board, !is_white) { // is king in check
+				continue
+			}
+			if IsSquareAttacked(p_f, board, !is_white) || // is passing squares in check
+				IsSquareAttacked(p_g, board, !is_white) {
 				continue
 			}
 			return 0, id, false
@@ -512,18 +454,23 @@ func NextCastleMove(id uint, board *Board, bs BoardState) (castle_type CastleTyp
 			}
 			p_d := MakePos(r, 3)
 			p_c := MakePos(r, 2)
+			p_b := MakePos(r, 1)
 			if board.GetPiece(p_c).GetType() != NoPiece ||
-				board.GetPiece(p_d).GetType() != NoPiece {
+				board.GetPiece(p_d).GetType() != NoPiece ||
+				board.GetPiece(p_b).GetType() != NoPiece {
+				continue
+			}
+			if board.GetPiece(MakePos(r, 0)) != Rook|(king&White) {
 				continue
 			}
-			if IsPosUnderAttack(MakePos(r, 4), board, is_white) { // is king in check
+			if IsSquareAttacked(MakePos(r, 4), board, !is_white) { // is king in check
 				continue
 			}
-			if IsPositionUnderAttack(p_c, board, is_white) || // is passing squares in check
-				IsPositionUnderAttack(p_d, board, is_white) {
+			if IsSquareAttacked(p_c, board, !is_white) || // is passing squares in check
+				IsSquareAttacked(p_d, board, !is_white) {
 				continue
 			}
-			return 1, id, true
+			return 1, id, false
 		}
 	}
 	return 0, id, true
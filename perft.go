@@ -0,0 +1,124 @@
+package main
+
+// leaf statistics of the move generation tree, same columns as in the standard perft tables
+type PerftStats struct {
+	Nodes      uint64
+	Captures   uint64
+	EnPassants uint64
+	Castles    uint64
+	Promotions uint64
+	Checks     uint64
+	Mates      uint64
+}
+
+func (s *PerftStats) Add(other PerftStats) {
+	s.Nodes += other.Nodes
+	s.Captures += other.Captures
+	s.EnPassants += other.EnPassants
+	s.Castles += other.Castles
+	s.Promotions += other.Promotions
+	s.Checks += other.Checks
+	s.Mates += other.Mates
+}
+
+// number of leaf nodes of the legal move tree of given depth
+func Perft(board *Board, bs BoardState, depth int) uint64 {
+	if depth == 0 {
+		return 1
+	}
+	moves := GenerateLegalMoves(board, bs)
+	if depth == 1 {
+		return uint64(len(moves))
+	}
+	var nodes uint64 = 0
+	for _, move := range moves {
+		next_bs := bs
+		undo := MakeMove(move, board, &next_bs)
+		nodes += Perft(board, next_bs, depth-1)
+		UnmakeMove(move, board, &next_bs, undo)
+	}
+	return nodes
+}
+
+// same as Perft, but uses the bitboard move generator
+func PerftBB(gp *GamePosition, depth int) uint64 {
+	if depth == 0 {
+		return 1
+	}
+	var buf [256]Move
+	moves := AppendLegalMovesBB(buf[:0], gp)
+	if depth == 1 {
+		return uint64(len(moves))
+	}
+	var nodes uint64 = 0
+	for _, move := range moves {
+		undo := gp.MakeMove(move)
+		nodes += PerftBB(gp, depth-1)
+		gp.UnmakeMove(move, undo)
+	}
+	return nodes
+}
+
+func perftLeafStats(move Move, board *Board, bs BoardState, undo MoveUndo) PerftStats {
+	stats := PerftStats{Nodes: 1}
+	if undo.Captured != NoPiece {
+		stats.Captures++
+	}
+	if undo.IsEnPassant {
+		stats.EnPassants++
+	}
+	if undo.IsCastle {
+		stats.Castles++
+	}
+	if move.GetPromote() != NoPiece {
+		stats.Promotions++
+	}
+	is_white := bs.Get_Turn()
+	if king_pos, found := FindKing(board, is_white); found && IsSquareAttacked(king_pos, board, !is_white) {
+		stats.Checks++
+		if len(GenerateLegalMoves(board, bs)) == 0 {
+			stats.Mates++
+		}
+	}
+	return stats
+}
+
+// same as Perft, but also counts special moves, checks and mates among the leaves
+func PerftDetailed(board *Board, bs BoardState, depth int) PerftStats {
+	var stats PerftStats
+	if depth == 0 {
+		stats.Nodes = 1
+		return stats
+	}
+	for _, move := range GenerateLegalMoves(board, bs) {
+		next_bs := bs
+		undo := MakeMove(move, board, &next_bs)
+		if depth == 1 {
+			stats.Add(perftLeafStats(move, board, next_bs, undo))
+		} else {
+			stats.Add(PerftDetailed(board, next_bs, depth-1))
+		}
+		UnmakeMove(move, board, &next_bs, undo)
+	}
+	return stats
+}
+
+type DivideEntry struct {
+	Move  Move
+	Nodes uint64
+}
+
+// perft of depth-1 for every legal root move
+func Divide(board *Board, bs BoardState, depth int) []DivideEntry {
+	var res []DivideEntry
+	if depth < 1 {
+		return res
+	}
+	for _, move := range GenerateLegalMoves(board, bs) {
+		next_bs := bs
+		undo := MakeMove(move, board, &next_bs)
+		res = append(res, DivideEntry{move, Perft(board, next_bs, depth-1)})
+		UnmakeMove(move, board, &next_bs, undo)
+	}
+	return res
+}
@@ -0,0 +1,77 @@
+package main
+
+import "testing"
+
+// positions and counts from https://www.chessprogramming.org/Perft_Results
+const (
+	perftInitial  = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+	perftKiwipete = "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1"
+	perftPos3     = "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1"
+	perftPos4     = "r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1"
+	perftPos5     = "rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8"
+	perftPos6     = "r4rk1/1pp1qppp/p1np1n2/2b1p1B1/2B1P1b1/P1NP1N2/1PP1QPPP/R4RK1 w - - 0 10"
+)
+
+func TestPerft(t *testing.T) {
+	cases := []struct {
+		fen   string
+		nodes []uint64 // by depth starting from 1
+	}{
+		{perftInitial, []uint64{20, 400, 8902, 197281}},
+		{perftKiwipete, []uint64{48, 2039, 97862}},
+		{perftPos3, []uint64{14, 191, 2812, 43238}},
+		{perftPos4, []uint64{6, 264, 9467}},
+		{perftPos5, []uint64{44, 1486, 62379}},
+		{perftPos6, []uint64{46, 2079, 89890}},
+	}
+	for _, c := range cases {
+		gp, er := ParseFEN(c.fen)
+		assert_er(er, t)
+		for i, expected := range c.nodes {
+			if nodes := Perft(&gp.Board, gp.State, i+1); nodes != expected {
+				t.Errorf("%s depth %d: %d nodes, expected %d", c.fen, i+1, nodes, expected)
+			}
+		}
+	}
+}
+
+func TestPerftDetailed(t *testing.T) {
+	cases := []struct {
+		fen   string
+		depth int
+		stats PerftStats
+	}{
+		{perftInitial, 3, PerftStats{8902, 34, 0, 0, 0, 12, 0}},
+		{perftInitial, 4, PerftStats{197281, 1576, 0, 0, 0, 469, 8}},
+		{perftKiwipete, 1, PerftStats{48, 8, 0, 2, 0, 0, 0}},
+		{perftKiwipete, 2, PerftStats{2039, 351, 1, 91, 0, 3, 0}},
+		{perftKiwipete, 3, PerftStats{97862, 17102, 45, 3162, 0, 993, 1}},
+		{perftPos3, 1, PerftStats{14, 1, 0, 0, 0, 2, 0}},
+		{perftPos3, 2, PerftStats{191, 14, 0, 0, 0, 10, 0}},
+		{perftPos3, 3, PerftStats{2812, 209, 2, 0, 0, 267, 0}},
+		{perftPos3, 4, PerftStats{43238, 3348, 123, 0, 0, 1680, 17}},
+		{perftPos4, 1, PerftStats{6, 0, 0, 0, 0, 0, 0}},
+		{perftPos4, 2, PerftStats{264, 87, 0, 6, 48, 10, 0}},
+		{perftPos4, 3, PerftStats{9467, 1021, 4, 0, 120, 38, 22}},
+	}
+	for _, c := range cases {
+		gp, er := ParseFEN(c.fen)
+		assert_er(er, t)
+		if stats := PerftDetailed(&gp.Board, gp.State, c.depth); stats != c.stats {
+			t.Errorf("%s depth %d: %+v, expected %+v", c.fen, c.depth, stats, c.stats)
+		}
+	}
+}
+
+func TestDivide(t *testing.T) {
+	gp, er := ParseFEN(perftKiwipete)
+	assert_er(er, t)
+	var total uint64 = 0
+	for _, entry := range Divide(&gp.Board, gp.State, 3) {
+		total += entry.Nodes
+		if entry.Move.String() == "e1g1" && entry.Nodes != 2059 {
+			t.Errorf("e1g1: %d nodes, expected 2059", entry.Nodes)
+		}
+	}
+	assert_equal(total, 97862, t)
+}
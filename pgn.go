@@ -0,0 +1,226 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type PGNTag struct {
+	Name  string
+	Value string
+}
+
+// sequence of moves with the comments before the first one
+type PGNLine struct {
+	Comments []string
+	Moves    []PGNNode
+}
+
+// engine evaluation from the white point of view, Mate is the number of moves to mate
+// positive if white mates, negative if black mates and 0 if there is no mate
+type PGNEval struct {
+	Centipawns int
+	Mate       int
+}
+
+type PGNNode struct {
+	Move       Move
+	NAGs       []uint8
+	Comments   []string      // comments after the move
+	Variations []PGNLine     // alternatives to the move, played from the position before it
+	Clock      time.Duration // remaining time of the side which made the move, [%clk]
+	HasClock   bool
+	Eval       PGNEval // [%eval]
+	HasEval    bool
+}
+
+// tags which an exported game always has, in the export order
+var pgnSevenTagRoster = [...]string{"Event", "Site", "Date", "Round", "White", "Black", "Result"}
+
+type PGNGame struct {
+	Tags   []PGNTag // in the order of appearance
+	Main   PGNLine
+	Result string // 1-0, 0-1, 1/2-1/2 or *
+}
+
+// value of the tag and true if the tag is present
+func (g *PGNGame) Tag(name string) (string, bool) {
+	for _, tag := range g.Tags {
+		if tag.Name == name {
+			return tag.Value, true
+		}
+	}
+	return "", false
+}
+
+// replaces the value of the tag or appends the tag
+func (g *PGNGame) SetTag(name, value string) {
+	for i := range g.Tags {
+		if g.Tags[i].Name == name {
+			g.Tags[i].Value = value
+			return
+		}
+	}
+	g.Tags = append(g.Tags, PGNTag{name, value})
+}
+
+// game with the seven tag roster and the moves played in the game
+func MakePGNGame(game *Game) *PGNGame {
+	res := &PGNGame{Result: "*"}
+	for _, name := range pgnSevenTagRoster {
+		res.SetTag(name, "?")
+	}
+	res.SetTag("Date", "????.??.??")
+	res.SetStartPosition(game.StartPosition())
+	for _, move := range game.Moves() {
+		res.Main.Moves = append(res.Main.Moves, PGNNode{Move: move})
+	}
+	switch status := game.Status(); {
+	case status == GameCheckmate && game.Position.State.Get_Turn():
+		res.Result = "0-1"
+	case status == GameCheckmate:
+		res.Result = "1-0"
+	case status.IsDraw():
+		res.Result = "1/2-1/2"
+	}
+	res.SetTag("Result", res.Result)
+	return res
+}
+
+func (g *PGNGame) removeTag(name string) {
+	for i := range g.Tags {
+		if g.Tags[i].Name == name {
+			g.Tags = append(g.Tags[:i], g.Tags[i+1:]...)
+			return
+		}
+	}
+}
+
+// sets SetUp and FEN tags for a non initial position and removes them otherwise
+func (g *PGNGame) SetStartPosition(gp GamePosition) {
+	fen := gp.String()
+	if fen == initialFEN {
+		g.removeTag("SetUp")
+		g.removeTag("FEN")
+		return
+	}
+	g.SetTag("SetUp", "1")
+	g.SetTag("FEN", fen)
+}
+
+// initial position or the position of the FEN tag
+func (g *PGNGame) StartPosition() (GamePosition, error) {
+	if fen, ok := g.Tag("FEN"); ok {
+		return ParseFEN(fen)
+	}
+	return MakeInitialGamePosition(), nil
+}
+
+// game with the mainline moves played
+func (g *PGNGame) Game() (*Game, error) {
+	start, er := g.StartPosition()
+	if er != nil {
+		return nil, er
+	}
+	game := MakeGame(start)
+	for _, node := range g.Main.Moves {
+		if er := game.Play(node.Move); er != nil {
+			return nil, er
+		}
+	}
+	return game, nil
+}
+
+// error of PGN parsing, Game is 1 based index of the game in the stream
+type PGNError struct {
+	Game   int
+	Line   int
+	Reason string
+	Err    error // underlying error if any
+}
+
+func (e *PGNError) Error() string {
+	return fmt.Sprintf("PGN game %d, line %d: %s", e.Game, e.Line, e.Reason)
+}
+
+func (e *PGNError) Unwrap() error {
+	return e.Err
+}
+
+func isPGNResult(s string) bool {
+	return s == "1-0" || s == "0-1" || s == "1/2-1/2" || s == "*"
+}
+
+var pgnCommandRegexp = regexp.MustCompile(`\[%(clk|eval)\s+([^\]]*)\]`)
+
+// parses h:mm:ss with optional fraction of a second
+func parsePGNClock(s string) (time.Duration, bool) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return 0, false
+	}
+	h, er1 := strconv.Atoi(parts[0])
+	m, er2 := strconv.Atoi(parts[1])
+	sec, er3 := strconv.ParseFloat(parts[2], 64)
+	if er1 != nil || er2 != nil || er3 != nil || h < 0 || m < 0 || m > 59 || sec < 0 || sec >= 60 {
+		return 0, false
+	}
+	d := time.Duration(h)*time.Hour + time.Duration(m)*time.Minute
+	return d + time.Duration(math.Round(sec*1000))*time.Millisecond, true
+}
+
+func formatPGNClock(d time.Duration) string {
+	ms := d.Milliseconds()
+	res := fmt.Sprintf("%d:%02d:%02d", ms/3600000, ms/60000%60, ms/1000%60)
+	if tenths := ms % 1000 / 100; tenths != 0 {
+		res += fmt.Sprintf(".%d", tenths)
+	}
+	return res
+}
+
+func parsePGNEval(s string) (PGNEval, bool) {
+	if mate, found := strings.CutPrefix(s, "#"); found {
+		n, er := strconv.Atoi(mate)
+		return PGNEval{Mate: n}, er == nil && n != 0
+	}
+	pawns, er := strconv.ParseFloat(s, 64)
+	if er != nil || math.IsNaN(pawns) || math.IsInf(pawns, 0) {
+		return PGNEval{}, false
+	}
+	return PGNEval{Centipawns: int(math.Round(pawns * 100))}, true
+}
+
+func (e PGNEval) String() string {
+	if e.Mate != 0 {
+		return fmt.Sprintf("#%d", e.Mate)
+	}
+	return strconv.FormatFloat(float64(e.Centipawns)/100, 'f', 2, 64)
+}
+
+// adds the comment, [%clk] and [%eval] commands are moved to Clock and Eval
+func (n *PGNNode) addComment(text string) {
+	text = pgnCommandRegexp.ReplaceAllStringFunc(text, func(command string) string {
+		m := pgnCommandRegexp.FindStringSubmatch(command)
+		value := strings.TrimSpace(m[2])
+		switch m[1] {
+		case "clk":
+			if d, ok := parsePGNClock(value); ok {
+				n.Clock, n.HasClock = d, true
+				return ""
+			}
+		case "eval":
+			if e, ok := parsePGNEval(value); ok {
+				n.Eval, n.HasEval = e, true
+				return ""
+			}
+		}
+		return command
+	})
+	if text = strings.Join(strings.Fields(text), " "); text != "" {
+		n.Comments = append(n.Comments, text)
+	}
+}
@@ -0,0 +1,427 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type pgnTokenKind uint8
+
+const (
+	pgnTokenEOF pgnTokenKind = iota
+	pgnTokenSymbol
+	pgnTokenString
+	pgnTokenComment
+	pgnTokenNAG
+	pgnTokenTagStart
+	pgnTokenTagEnd
+	pgnTokenVariationStart
+	pgnTokenVariationEnd
+)
+
+type pgnToken struct {
+	kind pgnTokenKind
+	text string
+	nag  uint8
+	line int
+}
+
+// suffix annotations and their NAG values
+var pgnSuffixNAGs = map[string]uint8{
+	"!":  1,
+	"?":  2,
+	"!!": 3,
+	"??": 4,
+	"!?": 5,
+	"?!": 6,
+}
+
+// reads games one by one from a PGN stream, only the current game is kept in memory
+type PGNReader struct {
+	r           *bufio.Reader
+	line        int
+	line_start  bool
+	game        int       // index of the current game
+	in_movetext bool      // tag section of the current game is read
+	peeked      *pgnToken // token returned back by unread
+}
+
+func MakePGNReader(r io.Reader) *PGNReader {
+	return &PGNReader{r: bufio.NewReader(r), line: 1, line_start: true}
+}
+
+func (p *PGNReader) readRune() (rune, error) {
+	c, _, er := p.r.ReadRune()
+	if er != nil {
+		return 0, er
+	}
+	p.line_start = c == '\n'
+	if c == '\n' {
+		p.line++
+	}
+	return c, nil
+}
+
+func (p *PGNReader) unreadRune(c rune) {
+	p.r.UnreadRune()
+	if c == '\n' {
+		p.line--
+	}
+	p.line_start = false
+}
+
+func (p *PGNReader) errorf(line int, format string, args ...any) *PGNError {
+	return &PGNError{Game: p.game, Line: line, Reason: fmt.Sprintf(format, args...)}
+}
+
+func isPGNSymbolRune(c rune) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+		strings.ContainsRune("_+#=:-/*", c)
+}
+
+// reads until the terminator which is consumed, returns false at the end of the stream
+func (p *PGNReader) readUntil(terminator rune, sb *strings.Builder) bool {
+	for {
+		c, er := p.readRune()
+		if er != nil {
+			return false
+		}
+		if c == terminator {
+			return true
+		}
+		if sb != nil {
+			sb.WriteRune(c)
+		}
+	}
+}
+
+func (p *PGNReader) unread(tok pgnToken) {
+	p.peeked = &tok
+}
+
+func (p *PGNReader) next() (pgnToken, error) {
+	if p.peeked != nil {
+		tok := *p.peeked
+		p.peeked = nil
+		return tok, nil
+	}
+	for {
+		line_start := p.line_start
+		c, er := p.readRune()
+		if er == io.EOF {
+			return pgnToken{kind: pgnTokenEOF, line: p.line}, nil
+		}
+		if er != nil {
+			return pgnToken{}, &PGNError{Game: p.game, Line: p.line, Reason: "read failed", Err: er}
+		}
+		line := p.line
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '.':
+		case c == '%' && line_start: // escape line
+			p.readUntil('\n', nil)
+		case c == ';':
+			var sb strings.Builder
+			p.readUntil('\n', &sb)
+			return pgnToken{kind: pgnTokenComment, text: strings.TrimSpace(sb.String()), line: line}, nil
+		case c == '{':
+			var sb strings.Builder
+			if !p.readUntil('}', &sb) {
+				return pgnToken{}, p.errorf(line, "unterminated comment")
+			}
+			return pgnToken{kind: pgnTokenComment, text: strings.TrimSpace(sb.String()), line: line}, nil
+		case c == '[':
+			return pgnToken{kind: pgnTokenTagStart, line: line}, nil
+		case c == ']':
+			return pgnToken{kind: pgnTokenTagEnd, line: line}, nil
+		case c == '(':
+			return pgnToken{kind: pgnTokenVariationStart, line: line}, nil
+		case c == ')':
+			return pgnToken{kind: pgnTokenVariationEnd, line: line}, nil
+		case c == '"':
+			return p.readString(line)
+		case c == '$':
+			return p.readNAG(line)
+		case c == '!' || c == '?':
+			return p.readSuffixNAG(c, line)
+		case isPGNSymbolRune(c):
+			var sb strings.Builder
+			sb.WriteRune(c)
+			for {
+				c, er = p.readRune()
+				if er != nil {
+					break
+				}
+				if !isPGNSymbolRune(c) {
+					p.unreadRune(c)
+					break
+				}
+				sb.WriteRune(c)
+			}
+			return pgnToken{kind: pgnTokenSymbol, text: sb.String(), line: line}, nil
+		default:
+			return pgnToken{}, p.errorf(line, "unexpected character %q", c)
+		}
+	}
+}
+
+func (p *PGNReader) readString(line int) (pgnToken, error) {
+	var sb strings.Builder
+	for {
+		c, er := p.readRune()
+		if er != nil || c == '\n' {
+			return pgnToken{}, p.errorf(line, "unterminated string")
+		}
+		switch c {
+		case '"':
+			return pgnToken{kind: pgnTokenString, text: sb.String(), line: line}, nil
+		case '\\':
+			c, er = p.readRune()
+			if er != nil {
+				return pgnToken{}, p.errorf(line, "unterminated string")
+			}
+		}
+		sb.WriteRune(c)
+	}
+}
+
+func (p *PGNReader) readNAG(line int) (pgnToken, error) {
+	value := 0
+	digits := 0
+	for {
+		c, er := p.readRune()
+		if er != nil {
+			break
+		}
+		if c < '0' || c > '9' {
+			p.unreadRune(c)
+			break
+		}
+		value = value*10 + int(c-'0')
+		digits++
+		if value > 255 {
+			return pgnToken{}, p.errorf(line, "NAG out of range")
+		}
+	}
+	if digits == 0 {
+		return pgnToken{}, p.errorf(line, "NAG without a number")
+	}
+	return pgnToken{kind: pgnTokenNAG, nag: uint8(value), line: line}, nil
+}
+
+func (p *PGNReader) readSuffixNAG(first rune, line int) (pgnToken, error) {
+	text := string(first)
+	c, er := p.readRune()
+	if er == nil {
+		if c == '!' || c == '?' {
+			text += string(c)
+		} else {
+			p.unreadRune(c)
+		}
+	}
+	nag, ok := pgnSuffixNAGs[text]
+	if !ok {
+		return pgnToken{}, p.errorf(line, "unknown annotation %q", text)
+	}
+	return pgnToken{kind: pgnTokenNAG, nag: nag, line: line}, nil
+}
+
+// skips the rest of the broken game, up to its result or the tag section of the next game
+func (p *PGNReader) skipGame() {
+	prev := pgnTokenEOF
+	for {
+		tok, er := p.next()
+		if er != nil {
+			if pe, ok := er.(*PGNError); ok && pe.Err != nil {
+				return // read failure
+			}
+			continue
+		}
+		switch {
+		case tok.kind == pgnTokenEOF:
+			return
+		case tok.kind == pgnTokenTagStart && p.in_movetext:
+			p.unread(tok)
+			return
+		case tok.kind == pgnTokenSymbol && isPGNResult(tok.text):
+			return
+		case tok.kind == pgnTokenSymbol && prev == pgnTokenTagStart, // tag name
+			tok.kind == pgnTokenTagStart, tok.kind == pgnTokenTagEnd, tok.kind == pgnTokenString:
+		default:
+			p.in_movetext = true
+		}
+		prev = tok.kind
+	}
+}
+
+// reads the next game, returns io.EOF when there are no more games
+// after an error the broken game is skipped and next call continues with the following game
+func (p *PGNReader) Next() (*PGNGame, error) {
+	tok, er := p.next()
+	if er != nil {
+		p.game++
+		p.in_movetext = false
+		p.skipGame()
+		return nil, er
+	}
+	if tok.kind == pgnTokenEOF {
+		return nil, io.EOF
+	}
+	p.game++
+	p.in_movetext = false
+	p.unread(tok)
+	game, er := p.readGame()
+	if er != nil {
+		p.skipGame()
+		return nil, er
+	}
+	return game, nil
+}
+
+func (p *PGNReader) readGame() (*PGNGame, error) {
+	game := &PGNGame{}
+	for {
+		tok, er := p.next()
+		if er != nil {
+			return nil, er
+		}
+		if tok.kind != pgnTokenTagStart {
+			p.unread(tok)
+			break
+		}
+		if er := p.readTag(game, tok.line); er != nil {
+			return nil, er
+		}
+	}
+
+	p.in_movetext = true
+	start, er := game.StartPosition()
+	if er != nil {
+		return nil, &PGNError{Game: p.game, Line: p.line, Reason: "bad FEN tag", Err: er}
+	}
+	end, er := p.readLine(&game.Main, start, 0)
+	if er != nil {
+		return nil, er
+	}
+	switch {
+	case end.kind == pgnTokenSymbol:
+		game.Result = end.text
+	case end.kind == pgnTokenTagStart: // next game without result
+		p.unread(end)
+		fallthrough
+	default:
+		game.Result = "*"
+		if result, ok := game.Tag("Result"); ok && isPGNResult(result) {
+			game.Result = result
+		}
+	}
+	return game, nil
+}
+
+func (p *PGNReader) readTag(game *PGNGame, line int) error {
+	name, er := p.next()
+	if er != nil {
+		return er
+	}
+	if name.kind != pgnTokenSymbol {
+		return p.errorf(line, "tag without a name")
+	}
+	value, er := p.next()
+	if er != nil {
+		return er
+	}
+	if value.kind != pgnTokenString {
+		return p.errorf(line, "tag %s without a value", name.text)
+	}
+	end, er := p.next()
+	if er != nil {
+		return er
+	}
+	if end.kind != pgnTokenTagEnd {
+		return p.errorf(line, "tag %s is not closed", name.text)
+	}
+	game.Tags = append(game.Tags, PGNTag{name.text, value.text})
+	return nil
+}
+
+// reads moves of the line played from gp, depth is the variation nesting level
+// returns the token which ended the line
+func (p *PGNReader) readLine(line *PGNLine, gp GamePosition, depth int) (pgnToken, error) {
+	before := gp // position before the last move
+	for {
+		tok, er := p.next()
+		if er != nil {
+			return tok, er
+		}
+		var last *PGNNode
+		if n := len(line.Moves); n > 0 {
+			last = &line.Moves[n-1]
+		}
+		switch tok.kind {
+		case pgnTokenEOF:
+			if depth > 0 {
+				return tok, p.errorf(tok.line, "unterminated variation")
+			}
+			return tok, nil
+		case pgnTokenTagStart:
+			if depth > 0 {
+				return tok, p.errorf(tok.line, "unterminated variation")
+			}
+			return tok, nil
+		case pgnTokenVariationEnd:
+			if depth == 0 {
+				return tok, p.errorf(tok.line, "unexpected )")
+			}
+			return tok, nil
+		case pgnTokenComment:
+			if last == nil {
+				line.Comments = append(line.Comments, tok.text)
+			} else {
+				last.addComment(tok.text)
+			}
+		case pgnTokenNAG:
+			if last == nil {
+				return tok, p.errorf(tok.line, "annotation before the first move")
+			}
+			last.NAGs = append(last.NAGs, tok.nag)
+		case pgnTokenVariationStart:
+			if last == nil {
+				return tok, p.errorf(tok.line, "variation before the first move")
+			}
+			var variation PGNLine
+			if _, er := p.readLine(&variation, before, depth+1); er != nil {
+				return tok, er
+			}
+			last.Variations = append(last.Variations, variation)
+		case pgnTokenSymbol:
+			if isPGNResult(tok.text) {
+				if depth > 0 {
+					return tok, p.errorf(tok.line, "result inside a variation")
+				}
+				return tok, nil
+			}
+			if isPGNMoveNumber(tok.text) {
+				continue
+			}
+			move, er := ParseSAN(tok.text, &gp.Board, gp.State)
+			if er != nil {
+				return tok, &PGNError{Game: p.game, Line: tok.line, Reason: er.Error(), Err: er}
+			}
+			before = gp
+			gp.MakeMove(move)
+			line.Moves = append(line.Moves, PGNNode{Move: move})
+		default:
+			return tok, p.errorf(tok.line, "unexpected token")
+		}
+	}
+}
+
+func isPGNMoveNumber(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+const pgnTestGames = `[Event "Casual"]
+[Site "?"]
+[Date "2024.01.02"]
+[Round "1"]
+[White "A \"Quoted\" Name"]
+[Black "B"]
+[Result "1-0"]
+[ECO "C20"]
+
+{Opening comment} 1. e4 e5 2. Qh5 $2 Nc6 (2... g6 {safe} 3. Qf3 (3. Qe5+ Qe7) Nf6)
+3. Bc4 Nf6?? ; greedy
+4. Qxf7# 1-0
+
+% escaped line
+[Event "Second"]
+[SetUp "1"]
+[FEN "4k3/8/8/8/8/8/4P3/4K3 w - - 0 1"]
+
+1. e4 Kd7 2. e5!? *
+`
+
+func readTestGames(pgn string, t *testing.T) []*PGNGame {
+	r := MakePGNReader(strings.NewReader(pgn))
+	var res []*PGNGame
+	for {
+		game, er := r.Next()
+		if er == io.EOF {
+			return res
+		}
+		assert_er(er, t)
+		res = append(res, game)
+	}
+}
+
+func TestPGNReader(t *testing.T) {
+	games := readTestGames(pgnTestGames, t)
+	assert_equal(len(games), 2, t)
+
+	game := games[0]
+	assert_equal(len(game.Tags), 8, t)
+	white, _ := game.Tag("White")
+	assert_equal(white, `A "Quoted" Name`, t)
+	_, found := game.Tag("Annotator")
+	assert_equal(found, false, t)
+	assert_equal(game.Result, "1-0", t)
+
+	main := game.Main
+	assert_equal(len(main.Comments), 1, t)
+	assert_equal(main.Comments[0], "Opening comment", t)
+	assert_equal(len(main.Moves), 7, t)
+	assert_equal(main.Moves[2].Move.UCI(), "d1h5", t)
+	assert_equal(len(main.Moves[2].NAGs), 1, t)
+	assert_equal(main.Moves[2].NAGs[0], uint8(2), t)
+	assert_equal(main.Moves[5].NAGs[0], uint8(4), t)
+	assert_equal(main.Moves[5].Comments[0], "greedy", t)
+	assert_equal(main.Moves[6].Move.UCI(), "h5f7", t)
+
+	assert_equal(len(main.Moves[3].Variations), 1, t)
+	variation := main.Moves[3].Variations[0]
+	assert_equal(len(variation.Moves), 3, t)
+	assert_equal(variation.Moves[0].Move.UCI(), "g7g6", t)
+	assert_equal(variation.Moves[0].Comments[0], "safe", t)
+	assert_equal(len(variation.Moves[1].Variations), 1, t)
+	nested := variation.Moves[1].Variations[0]
+	assert_equal(nested.Moves[0].Move.UCI(), "h5e5", t)
+	assert_equal(nested.Moves[1].Move.UCI(), "d8e7", t)
+
+	g, er := game.Game()
+	assert_er(er, t)
+	assert_equal(g.Status(), GameCheckmate, t)
+
+	game = games[1]
+	assert_equal(game.Result, "*", t)
+	assert_equal(len(game.Main.Moves), 3, t)
+	assert_equal(game.Main.Moves[2].NAGs[0], uint8(5), t)
+	g, er = game.Game()
+	assert_er(er, t)
+	assert_equal(g.Position.String(), "8/3k4/8/4P3/8/8/8/4K3 b - - 0 2", t)
+}
+
+func TestPGNReaderWithoutResult(t *testing.T) {
+	games := readTestGames("[Result \"0-1\"]\n1. f3 e5 2. g4\n[Event \"Next\"]\n1. d4", t)
+	assert_equal(len(games), 2, t)
+	assert_equal(games[0].Result, "0-1", t)
+	assert_equal(len(games[0].Main.Moves), 3, t)
+	assert_equal(games[1].Result, "*", t)
+	assert_equal(len(games[1].Main.Moves), 1, t)
+}
+
+func TestPGNReaderErrors(t *testing.T) {
+	pgn := "[Event \"1\"]\n1. e4 e5 *\n\n[Event \"2\"]\n\n1. e4 e5\n2. Ke3 Nc6 1-0\n[Event \"3\"]\n1. d4 (1. e4 e5 *\n\n[Event \"4\"]\n1. c4 *\n"
+	r := MakePGNReader(strings.NewReader(pgn))
+
+	game, er := r.Next()
+	assert_er(er, t)
+	assert_equal(len(game.Main.Moves), 2, t)
+
+	_, er = r.Next()
+	var pe *PGNError
+	if !errors.As(er, &pe) {
+		t.Fatalf("expected PGNError, got %v", er)
+	}
+	assert_equal(pe.Game, 2, t)
+	assert_equal(pe.Line, 7, t)
+	var illegal *ErrorIllegalSAN
+	assert_equal(errors.As(er, &illegal), true, t)
+
+	_, er = r.Next()
+	if !errors.As(er, &pe) {
+		t.Fatalf("expected PGNError, got %v", er)
+	}
+	assert_equal(pe.Game, 3, t)
+	assert_equal(pe.Line, 9, t)
+
+	game, er = r.Next()
+	assert_er(er, t)
+	event, _ := game.Tag("Event")
+	assert_equal(event, "4", t)
+
+	_, er = r.Next()
+	assert_equal(er, io.EOF, t)
+}
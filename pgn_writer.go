@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// maximal length of the movetext line in the export format
+const pgnLineWidth = 80
+
+// movetext tokens of a game, they are wrapped into lines once the game is complete
+type pgnTokens struct {
+	tokens []string
+	prefix string // prepended to the next token
+}
+
+func (pt *pgnTokens) write(token string) {
+	pt.tokens = append(pt.tokens, pt.prefix+token)
+	pt.prefix = ""
+}
+
+// comment is written word by word so that it can be wrapped
+func (pt *pgnTokens) writeComment(text string) {
+	words := strings.Fields(strings.ReplaceAll(text, "}", ""))
+	if len(words) == 0 {
+		pt.write("{}")
+		return
+	}
+	words[0] = "{" + words[0]
+	words[len(words)-1] += "}"
+	for _, word := range words {
+		pt.write(word)
+	}
+}
+
+func (pt *pgnTokens) writeVariation(line *PGNLine, gp GamePosition) {
+	n := len(pt.tokens)
+	pt.prefix = "("
+	pt.writeLine(line, gp)
+	if len(pt.tokens) == n {
+		pt.write(")")
+		return
+	}
+	pt.tokens[len(pt.tokens)-1] += ")"
+}
+
+func (pt *pgnTokens) writeLine(line *PGNLine, gp GamePosition) {
+	for _, comment := range line.Comments {
+		pt.writeComment(comment)
+	}
+	need_number := true // black move needs N... after a comment or a variation
+	for i := range line.Moves {
+		node := &line.Moves[i]
+		is_white := gp.State.Get_Turn()
+		san := ToSAN(node.Move, &gp.Board, gp.State)
+		switch {
+		case is_white:
+			san = fmt.Sprintf("%d. %s", gp.FullMoves, san)
+		case need_number:
+			san = fmt.Sprintf("%d... %s", gp.FullMoves, san)
+		}
+		for _, token := range strings.Split(san, " ") {
+			pt.write(token)
+		}
+		need_number = false
+		for _, nag := range node.NAGs {
+			pt.write(fmt.Sprintf("$%d", nag))
+		}
+
+		var commands []string
+		if node.HasClock {
+			commands = append(commands, "[%clk "+formatPGNClock(node.Clock)+"]")
+		}
+		if node.HasEval {
+			commands = append(commands, "[%eval "+node.Eval.String()+"]")
+		}
+		if comment := strings.Join(append(commands, node.Comments...), " "); comment != "" {
+			pt.writeComment(comment)
+			need_number = true
+		}
+
+		for j := range node.Variations {
+			pt.writeVariation(&node.Variations[j], gp)
+			need_number = true
+		}
+		gp.MakeMove(AddMoveFlags(node.Move, &gp.Board, gp.State))
+	}
+}
+
+func writePGNTag(w *bufio.Writer, name, value string) {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	fmt.Fprintf(w, "[%s \"%s\"]\n", name, value)
+}
+
+// writes the game in the PGN export format: the seven tag roster first, SetUp and FEN
+// for a non initial start position, movetext in SAN wrapped at 80 columns
+func WritePGN(w io.Writer, game *PGNGame) error {
+	start, er := game.StartPosition()
+	if er != nil {
+		return er
+	}
+	result := game.Result
+	if !isPGNResult(result) {
+		result = "*"
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, name := range pgnSevenTagRoster {
+		value, found := game.Tag(name)
+		switch {
+		case name == "Result":
+			value = result
+		case !found && name == "Date":
+			value = "????.??.??"
+		case !found:
+			value = "?"
+		}
+		writePGNTag(bw, name, value)
+	}
+	if fen := start.String(); fen != initialFEN {
+		writePGNTag(bw, "SetUp", "1")
+		writePGNTag(bw, "FEN", fen)
+	}
+	for _, tag := range game.Tags {
+		switch tag.Name {
+		case "Event", "Site", "Date", "Round", "White", "Black", "Result", "SetUp", "FEN":
+			continue
+		}
+		writePGNTag(bw, tag.Name, tag.Value)
+	}
+	bw.WriteByte('\n')
+
+	var pt pgnTokens
+	pt.writeLine(&game.Main, start)
+	pt.write(result)
+	line_len := 0
+	for _, token := range pt.tokens {
+		switch {
+		case line_len == 0:
+		case line_len+1+len(token) > pgnLineWidth:
+			bw.WriteByte('\n')
+			line_len = 0
+		default:
+			bw.WriteByte(' ')
+			line_len++
+		}
+		bw.WriteString(token)
+		line_len += len(token)
+	}
+	bw.WriteString("\n\n")
+	return bw.Flush()
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeTestPGN(game *PGNGame, t *testing.T) string {
+	var sb strings.Builder
+	assert_er(WritePGN(&sb, game), t)
+	return sb.String()
+}
+
+func TestWritePGN(t *testing.T) {
+	games := readTestGames(pgnTestGames, t)
+	expected := `[Event "Casual"]
+[Site "?"]
+[Date "2024.01.02"]
+[Round "1"]
+[White "A \"Quoted\" Name"]
+[Black "B"]
+[Result "1-0"]
+[ECO "C20"]
+
+{Opening comment} 1. e4 e5 2. Qh5 $2 Nc6 (2... g6 {safe} 3. Qf3 (3. Qxe5+ Qe7)
+3... Nf6) 3. Bc4 Nf6 $4 {greedy} 4. Qxf7# 1-0
+
+`
+	assert_equal(writeTestPGN(games[0], t), expected, t)
+
+	expected = `[Event "Second"]
+[Site "?"]
+[Date "????.??.??"]
+[Round "?"]
+[White "?"]
+[Black "?"]
+[Result "*"]
+[SetUp "1"]
+[FEN "4k3/8/8/8/8/8/4P3/4K3 w - - 0 1"]
+
+1. e4 Kd7 2. e5 $5 *
+
+`
+	assert_equal(writeTestPGN(games[1], t), expected, t)
+}
+
+func TestWritePGNRoundTrip(t *testing.T) {
+	games := readTestGames(pgnTestGames, t)
+	for _, game := range games {
+		text := writeTestPGN(game, t)
+		read := readTestGames(text, t)
+		assert_equal(len(read), 1, t)
+		assert_equal(writeTestPGN(read[0], t), text, t)
+	}
+}
+
+func TestWritePGNAnnotations(t *testing.T) {
+	game := MakePGNGame(NewGame())
+	game.Main.Moves = []PGNNode{
+		{Move: makeTestMove("e2", "e4", NoPiece), Clock: 59*time.Second + 500*time.Millisecond, HasClock: true},
+		{Move: makeTestMove("e7", "e5", NoPiece), Eval: PGNEval{Centipawns: 25}, HasEval: true},
+		{Move: makeTestMove("g1", "f3", NoPiece), Eval: PGNEval{Mate: -3}, HasEval: true, Comments: []string{"blunder"}},
+	}
+	text := writeTestPGN(game, t)
+	movetext := "1. e4 {[%clk 0:00:59.5]} 1... e5 {[%eval 0.25]} 2. Nf3 {[%eval #-3] blunder} *\n\n"
+	if !strings.HasSuffix(text, movetext) {
+		t.Errorf("unexpected movetext in\n%s", text)
+	}
+
+	read := readTestGames(text, t)[0]
+	assert_equal(read.Main.Moves[0].HasClock, true, t)
+	assert_equal(read.Main.Moves[0].Clock, 59*time.Second+500*time.Millisecond, t)
+	assert_equal(read.Main.Moves[1].Eval, PGNEval{Centipawns: 25}, t)
+	assert_equal(read.Main.Moves[2].Eval, PGNEval{Mate: -3}, t)
+	assert_equal(len(read.Main.Moves[2].Comments), 1, t)
+	assert_equal(read.Main.Moves[2].Comments[0], "blunder", t)
+}
+
+func TestWritePGNFromPosition(t *testing.T) {
+	start, er := ParseFEN("7k/8/6K1/8/8/8/8/R7 w - - 0 1")
+	assert_er(er, t)
+	g := MakeGame(start)
+	assert_er(g.Play(makeTestMove("a1", "a8", NoPiece)), t)
+
+	text := writeTestPGN(MakePGNGame(g), t)
+	expected := "[Result \"1-0\"]\n[SetUp \"1\"]\n[FEN \"7k/8/6K1/8/8/8/8/R7 w - - 0 1\"]\n\n1. Ra8# 1-0\n\n"
+	if !strings.HasSuffix(text, expected) {
+		t.Errorf("unexpected PGN\n%s", text)
+	}
+}
+
+func TestWritePGNFiftyMoveRule(t *testing.T) {
+	start, er := ParseFEN("4k3/8/8/8/8/8/8/R3K3 w - - 99 80")
+	assert_er(er, t)
+	g := MakeGame(start)
+	assert_er(g.Play(makeTestMove("a1", "a2", NoPiece)), t)
+	assert_equal(g.Status(), GameFiftyMoveDraw, t)
+
+	// the draw is not claimed, so the game is still going on
+	text := writeTestPGN(MakePGNGame(g), t)
+	if !strings.HasSuffix(text, "80. Ra2 *\n\n") {
+		t.Errorf("unexpected PGN\n%s", text)
+	}
+}
+
+func TestWritePGNWrapsLines(t *testing.T) {
+	g := NewGame()
+	for i := 0; i < 60; i++ {
+		g.Play(g.LegalMoves()[0])
+	}
+	text := writeTestPGN(MakePGNGame(g), t)
+	for _, line := range strings.Split(text, "\n") {
+		if len(line) > pgnLineWidth {
+			t.Errorf("line is longer than %d: %q", pgnLineWidth, line)
+		}
+	}
+	read := readTestGames(text, t)
+	assert_equal(len(read[0].Main.Moves), len(g.Moves()), t)
+}
+
+func TestWritePGNUnflaggedMoves(t *testing.T) {
+	game := MakePGNGame(NewGame())
+	for _, m := range [][2]string{{"e2", "e4"}, {"e7", "e5"}, {"g1", "f3"}, {"b8", "c6"}, {"f1", "c4"}, {"g8", "f6"}, {"e1", "g1"}, {"f8", "c5"}, {"f1", "e1"}, {"d7", "d5"}, {"e4", "d5"}} {
+		game.Main.Moves = append(game.Main.Moves, PGNNode{Move: makeTestMove(m[0], m[1], NoPiece)})
+	}
+	text := writeTestPGN(game, t)
+	movetext := "1. e4 e5 2. Nf3 Nc6 3. Bc4 Nf6 4. O-O Bc5 5. Re1 d5 6. exd5 *\n\n"
+	if !strings.HasSuffix(text, movetext) {
+		t.Errorf("unexpected movetext in\n%s", text)
+	}
+
+	game = MakePGNGame(NewGame())
+	for _, m := range [][2]string{{"e2", "e4"}, {"g8", "f6"}, {"e4", "e5"}, {"d7", "d5"}, {"e5", "d6"}, {"d8", "d6"}, {"d2", "d4"}, {"d6", "d4"}} {
+		game.Main.Moves = append(game.Main.Moves, PGNNode{Move: makeTestMove(m[0], m[1], NoPiece)})
+	}
+	text = writeTestPGN(game, t)
+	movetext = "1. e4 Nf6 2. e5 d5 3. exd6 Qxd6 4. d4 Qxd4 *\n\n"
+	if !strings.HasSuffix(text, movetext) {
+		t.Errorf("unexpected movetext in\n%s", text)
+	}
+}
@@ -106,3 +106,10 @@ type ErrorUnknownPieceLiteral struct {
 func (e *ErrorUnknownPieceLiteral) Error() string {
 	return fmt.Sprintf("unknown piece literal: %c", e.literal)
 }
+
+func MakePiece(piece_type Piece, is_white bool) Piece {
+	if is_white {
+		return piece_type | White
+	}
+	return piece_type
+}
@@ -1,6 +1,10 @@
 package main
 
-import "golang.org/x/exp/constraints"
+import (
+	"math"
+
+	"golang.org/x/exp/constraints"
+)
 
 func UpdateCastle(pos Position, bs *BoardState) {
 	switch pos {
@@ -23,28 +27,117 @@ func UpdateCastle(pos Position, bs *BoardState) {
 	}
 }
 
-func MakeMove(move Move, board *Board, bs *BoardState) {
+// castle rook move for the king moving from start to end, ok is false if it is not a castle
+func castleRookMove(start Position, end Position) (rook_start Position, rook_end Position, ok bool) {
+	r := start.GetRow()
+	if start.GetCol() != 4 || end.GetRow() != r {
+		return 0, 0, false
+	}
+	switch end.GetCol() {
+	case 6:
+		return MakePos(r, 7), MakePos(r, 5), true
+	case 2:
+		return MakePos(r, 0), MakePos(r, 3), true
+	}
+	return 0, 0, false
+}
+
+// information required by UnmakeMove to revert a move made by MakeMove
+type MoveUndo struct {
+	Captured    Piece      // captured piece, including the pawn taken en passant
+	PrevState   BoardState // board state before the move
+	IsEnPassant bool
+	IsCastle    bool
+	HashDelta   uint64 // xor of the position hashes before and after the move
+}
+
+// makes the move, missing flags of castling, double push and en passant are restored
+func MakeMove(move Move, board *Board, bs *BoardState) MoveUndo {
 	start := move.GetStart()
 	end := move.GetEnd()
+	piece := board.GetPiece(start)
+	captured := board.GetPiece(end)
+	if move.GetFlags() == 0 && (piece.GetType() == King && Abs(end.GetCol()-start.GetCol()) == 2 ||
+		piece.GetType() == Pawn && (Abs(end.GetRow()-start.GetRow()) == 2 || start.GetCol() != end.GetCol() && captured == NoPiece)) {
+		move = AddMoveFlags(move, board, *bs)
+	}
+	undo := MoveUndo{Captured: captured, PrevState: *bs}
 	UpdateCastle(start, bs)
 	UpdateCastle(end, bs)
-	piece := board.GetPiece(start)
+	h_moves := bs.Get_HMoves()
+
+	*bs = bs.Set_IsEnPos(false)
+	*bs = bs.Set_EnPos(MakePos(0, 0)) // for consistency
+
+	board.SetPiece(start, NoPiece)
+	board.SetPiece(end, piece)
+	undo.HashDelta = zobristPiece(piece, start) ^ zobristPiece(piece, end) ^ zobristPiece(captured, end)
 	switch piece.GetType() {
-	case Knight:
-		fallthrough
-	case Bishop:
-		fallthrough
-	case Rook:
-		fallthrough
-	case Queen:
-		board.SetPiece(start, NoPiece)
-		board.SetPiece(end, piece)
 	case Pawn:
-		// TODO
+		h_moves = 0
+		rs := start.GetRow()
+		re := end.GetRow()
+		switch {
+		case move.IsDoublePush(): // square behind the pawn can be taken en passant
+			*bs = bs.Set_IsEnPos(true)
+			*bs = bs.Set_EnPos(MakePos((rs+re)/2, start.GetCol()))
+		case move.IsEnPassant():
+			capture_pos := MakePos(rs, end.GetCol())
+			undo.Captured = board.GetPiece(capture_pos)
+			undo.IsEnPassant = true
+			undo.HashDelta ^= zobristPiece(undo.Captured, capture_pos)
+			board.SetPiece(capture_pos, NoPiece)
+		}
+		if promote := move.GetPromote(); promote != NoPiece {
+			board.SetPiece(end, promote|(piece&White))
+			undo.HashDelta ^= zobristPiece(piece, end) ^ zobristPiece(promote|(piece&White), end)
+		}
 	case King:
-		// TODO
+		if move.IsCastle() {
+			if rook_start, rook_end, ok := castleRookMove(start, end); ok {
+				rook := board.GetPiece(rook_start)
+				board.SetPiece(rook_end, rook)
+				board.SetPiece(rook_start, NoPiece)
+				undo.IsCastle = true
+				undo.HashDelta ^= zobristPiece(rook, rook_start) ^ zobristPiece(rook, rook_end)
+			}
+		}
+	}
+
+	if captured != NoPiece {
+		h_moves = 0
+	} else if piece.GetType() != Pawn && h_moves < math.MaxUint8 {
+		h_moves++
+	}
+	*bs = bs.Set_HMoves(h_moves)
+	*bs = bs.Set_Turn(!bs.Get_Turn())
+	undo.HashDelta ^= undo.PrevState.Hash() ^ bs.Hash()
+	return undo
+}
+
+// reverts move made by MakeMove, undo should be the value returned by it
+func UnmakeMove(move Move, board *Board, bs *BoardState, undo MoveUndo) {
+	start := move.GetStart()
+	end := move.GetEnd()
+	piece := board.GetPiece(end)
+	if move.GetPromote() != NoPiece {
+		piece = Pawn | (piece & White)
 	}
+	board.SetPiece(start, piece)
 
+	switch {
+	case undo.IsEnPassant:
+		board.SetPiece(end, NoPiece)
+		board.SetPiece(MakePos(start.GetRow(), end.GetCol()), undo.Captured)
+	case undo.IsCastle:
+		board.SetPiece(end, NoPiece)
+		rook_start, rook_end, _ := castleRookMove(start, end)
+		board.SetPiece(rook_start, board.GetPiece(rook_end))
+		board.SetPiece(rook_end, NoPiece)
+	default:
+		board.SetPiece(end, undo.Captured)
+	}
+	*bs = undo.PrevState
 }
 
 func isTakenByFriend(board *Board, pos Position, is_white bool) bool {
@@ -65,8 +158,7 @@ func Abs[T constraints.Signed](x T) T {
 
 // return true if position is under attack, is_white - determines color of the piece at this position
 func IsPosUnderAttack(pos Position, board *Board, is_white bool) bool {
-	// TODO
-	return false
+	return IsSquareAttacked(pos, board, !is_white)
 }
 
 func IsPawnMovePossible(move Move, board *Board, is_white bool, is_en bool, en_pos Position) bool {
@@ -87,8 +179,7 @@ func IsPawnMovePossible(move Move, board *Board, is_white bool, is_en bool, en_p
 		if board.GetPiece(end) != NoPiece {
 			return false
 		}
-		return true
-	case ce == cs+1 || ce == cs-1 && re == rs+dir: // capture
+	case (ce == cs+1 || ce == cs-1) && re == rs+dir: // capture
 		end_p := board.GetPiece(end)
 		if end_p != NoPiece {
 			if end_p.IsWhite() == is_white {
@@ -104,6 +195,9 @@ func IsPawnMovePossible(move Move, board *Board, is_white bool, is_en bool, en_p
 			}
 		}
 	case ce == cs && re == rs+dir*2: // move forward 2
+		if (is_white && rs != 1) || (!is_white && rs != 6) {
+			return false
+		}
 		if board.GetPiece(end) != NoPiece {
 			return false
 		}
@@ -114,10 +208,18 @@ func IsPawnMovePossible(move Move, board *Board, is_white bool, is_en bool, en_p
 		return false
 	}
 
-	if move.GetPromote() != NoPiece {
-		if (is_white && re != 7) || (!is_white && re != 0) {
+	is_last_row := (is_white && re == 7) || (!is_white && re == 0)
+	switch move.GetPromote() {
+	case NoPiece:
+		if is_last_row {
 			return false
 		}
+	case Knight, Bishop, Rook, Queen:
+		if !is_last_row {
+			return false
+		}
+	default:
+		return false
 	}
 
 	return true
@@ -194,6 +296,9 @@ func IsBishopMovePossible(move Move, board *Board, is_white bool) bool {
 	if start == end {
 		return false
 	}
+	if isTakenByFriend(board, end, is_white) {
+		return false
+	}
 	dist := Abs(rs - re)
 	if dist != Abs(cs-ce) {
 		return false
@@ -262,6 +367,9 @@ func IsKingMovePossible(move Move, board *Board, bs BoardState, is_white bool) b
 				return false
 			}
 		}
+		if isTaken(board, MakePos(r, 5)) || isTaken(board, MakePos(r, 6)) {
+			return false
+		}
 		for ci := int8(4); ci < 7; ci++ {
 			if IsPosUnderAttack(MakePos(r, ci), board, is_white) {
 				return false
@@ -277,6 +385,9 @@ func IsKingMovePossible(move Move, board *Board, bs BoardState, is_white bool) b
 				return false
 			}
 		}
+		if isTaken(board, MakePos(r, 1)) || isTaken(board, MakePos(r, 2)) || isTaken(board, MakePos(r, 3)) {
+			return false
+		}
 		for ci := int8(2); ci <= 4; ci++ {
 			if IsPosUnderAttack(MakePos(r, ci), board, is_white) {
 				return false
@@ -288,6 +399,7 @@ func IsKingMovePossible(move Move, board *Board, bs BoardState, is_white bool) b
 	return true
 }
 
+// flags of the move are ignored
 func IsMovePossible(move Move, board *Board, bs BoardState) bool {
 	piece := board.GetPiece(move.GetStart())
 	is_white := bs.Get_Turn()
@@ -295,6 +407,9 @@ func IsMovePossible(move Move, board *Board, bs BoardState) bool {
 	if is_white != piece.IsWhite() {
 		return false
 	}
+	if piece.GetType() != Pawn && move.GetPromote() != NoPiece {
+		return false
+	}
 
 	var is_possible bool
 	switch piece.GetType() {
@@ -318,22 +433,5 @@ func IsMovePossible(move Move, board *Board, bs BoardState) bool {
 		return false
 	}
 
-	var king Piece
-	if is_white {
-		king = W_King
-	} else {
-		king = B_King
-	}
-
-	for i, piece := range board {
-		if piece != king {
-			continue
-		}
-		if IsPosUnderAttack(Position(i), board, is_white) {
-			is_possible = false
-		}
-		break
-	}
-
-	return false
+	return IsMoveSafe(AddMoveFlags(move, board, bs), board, bs)
 }
@@ -0,0 +1,118 @@
+package main
+
+import "testing"
+
+func makeTestMove(start, end string, promote Piece) Move {
+	var m Move
+	s, _ := MakePiecePosFromFEN(start)
+	e, _ := MakePiecePosFromFEN(end)
+	m = m.SetStart(s)
+	m = m.SetEnd(e)
+	m = m.SetPromote(promote)
+	return m
+}
+
+func playTestMove(board *Board, bs *BoardState, start, end string, promote Piece) MoveUndo {
+	return MakeMove(AddMoveFlags(makeTestMove(start, end, promote), board, *bs), board, bs)
+}
+
+func assert_board(board *Board, expected string, t *testing.T) {
+	fen, er := board.FEN()
+	assert_er(er, t)
+	if fen != expected {
+		t.Errorf("board %s, expected %s", fen, expected)
+	}
+}
+
+func TestMakeMovePawn(t *testing.T) {
+	board := MakeInitialBoard()
+	bs := MakeInitialBoardState()
+
+	playTestMove(&board, &bs, "e2", "e4", NoPiece)
+	assert_board(&board, "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR", t)
+	assert_equal(bs.FEN(), "b KQkq e3 0", t)
+
+	playTestMove(&board, &bs, "g8", "f6", NoPiece)
+	assert_equal(bs.FEN(), "w KQkq - 1", t)
+
+	playTestMove(&board, &bs, "e4", "e5", NoPiece)
+	playTestMove(&board, &bs, "d7", "d5", NoPiece)
+	assert_equal(bs.FEN(), "w KQkq d6 0", t)
+
+	playTestMove(&board, &bs, "e5", "d6", NoPiece) // en passant
+	assert_board(&board, "rnbqkb1r/ppp1pppp/3P1n2/8/8/8/PPPP1PPP/RNBQKBNR", t)
+	assert_equal(bs.FEN(), "b KQkq - 0", t)
+}
+
+func TestMakeMovePromotion(t *testing.T) {
+	board, er := MakeBoardFromFEN("1r2k3/P7/8/8/8/8/8/4K3")
+	assert_er(er, t)
+	bs, er := MakeBoardStateFromFEN("w - - 1")
+	assert_er(er, t)
+
+	playTestMove(&board, &bs, "a7", "b8", Knight)
+	assert_board(&board, "1N2k3/8/8/8/8/8/8/4K3", t)
+	assert_equal(bs.FEN(), "b - - 0", t)
+}
+
+func TestMakeMoveCastle(t *testing.T) {
+	board, er := MakeBoardFromFEN("r3k2r/8/8/8/8/8/8/R3K2R")
+	assert_er(er, t)
+	bs := MakeInitialBoardState()
+
+	playTestMove(&board, &bs, "e1", "g1", NoPiece)
+	assert_board(&board, "r3k2r/8/8/8/8/8/8/R4RK1", t)
+	assert_equal(bs.FEN(), "b kq - 1", t)
+
+	playTestMove(&board, &bs, "e8", "c8", NoPiece)
+	assert_board(&board, "2kr3r/8/8/8/8/8/8/R4RK1", t)
+	assert_equal(bs.FEN(), "w - - 2", t)
+}
+
+func TestMakeMoveWithoutFlags(t *testing.T) {
+	board, bs := makeTestPosition("r3k2r/8/8/3pP3/8/8/4P3/R3K2R w KQkq d6 0 1", t)
+	moves := []Move{
+		makeTestMove("e5", "d6", NoPiece), // en passant
+		makeTestMove("e2", "e4", NoPiece), // double push
+		makeTestMove("e1", "g1", NoPiece), // castle
+		makeTestMove("e1", "c1", NoPiece), // castle
+	}
+	for _, move := range moves {
+		flagged_board, flagged_bs := board, bs
+		flagged_undo := MakeMove(AddMoveFlags(move, &board, bs), &flagged_board, &flagged_bs)
+		board_copy, bs_copy := board, bs
+		undo := MakeMove(move, &board_copy, &bs_copy)
+		assert_equal(board_copy, flagged_board, t)
+		assert_equal(bs_copy, flagged_bs, t)
+		assert_equal(undo, flagged_undo, t)
+	}
+}
+
+func TestUnmakeMove(t *testing.T) {
+	board, er := MakeBoardFromFEN("r3k2r/1P6/8/3pP3/8/8/8/R3K2R")
+	assert_er(er, t)
+	bs, er := MakeBoardStateFromFEN("w KQkq d6 4")
+	assert_er(er, t)
+
+	moves := []Move{
+		makeTestMove("e5", "d6", NoPiece), // en passant
+		makeTestMove("e1", "g1", NoPiece), // castle
+		makeTestMove("e1", "c1", NoPiece), // castle
+		makeTestMove("b7", "a8", Queen),   // capture with promotion
+		makeTestMove("b7", "b8", Rook),    // promotion
+		makeTestMove("a1", "a8", NoPiece), // capture, both sides lose castle
+		makeTestMove("h1", "h2", NoPiece),
+	}
+	for _, move := range moves {
+		move = AddMoveFlags(move, &board, bs)
+		board_copy := board
+		bs_copy := bs
+		undo := MakeMove(move, &board_copy, &bs_copy)
+		if board_copy == board {
+			t.Errorf("move %v did not change the board", move)
+		}
+		UnmakeMove(move, &board_copy, &bs_copy, undo)
+		assert_equal(board_copy, board, t)
+		assert_equal(bs_copy, bs, t)
+	}
+}
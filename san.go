@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+type ErrorIllegalSAN struct {
+	san string
+}
+
+func (e *ErrorIllegalSAN) Error() string {
+	return fmt.Sprintf("illegal move: %q", e.san)
+}
+
+type ErrorAmbiguousSAN struct {
+	san string
+}
+
+func (e *ErrorAmbiguousSAN) Error() string {
+	return fmt.Sprintf("ambiguous move: %q", e.san)
+}
+
+// upper case literal of the piece type used by SAN
+func sanPieceLiteral(p Piece) string {
+	return MakePiece(p.GetType(), true).String()
+}
+
+// "+" if the move gives check, "#" if it gives mate
+func checkSuffix(move Move, board *Board, bs BoardState) string {
+	undo := MakeMove(move, board, &bs)
+	defer UnmakeMove(move, board, &bs, undo)
+	if !InCheck(board, bs) {
+		return ""
+	}
+	var buf [256]Move
+	if len(AppendLegalMoves(buf[:0], board, bs)) == 0 {
+		return "#"
+	}
+	return "+"
+}
+
+// Standard Algebraic Notation of the legal move, e.g. Nbd7, exd6, O-O-O, e8=Q+ or Qxf7#
+func ToSAN(move Move, board *Board, bs BoardState) string {
+	move = AddMoveFlags(move, board, bs)
+	start := move.GetStart()
+	end := move.GetEnd()
+	piece := board.GetPiece(start)
+
+	var res string
+	switch {
+	case move.IsKingCastle():
+		res = "O-O"
+	case move.IsQueenCastle():
+		res = "O-O-O"
+	case piece.GetType() == Pawn:
+		if move.IsCapture() {
+			res = string(rune('a'+start.GetCol())) + "x"
+		}
+		res += end.String()
+		if p := move.GetPromote(); p != NoPiece {
+			res += "=" + sanPieceLiteral(p)
+		}
+	default:
+		res = sanPieceLiteral(piece)
+		// other pieces of the same type which can move to the same square
+		var buf [256]Move
+		same_file, same_rank, ambiguous := false, false, false
+		for _, m := range AppendLegalMoves(buf[:0], board, bs) {
+			if m.GetEnd() != end || m.GetStart() == start || board.GetPiece(m.GetStart()) != piece {
+				continue
+			}
+			ambiguous = true
+			same_file = same_file || m.GetStart().GetCol() == start.GetCol()
+			same_rank = same_rank || m.GetStart().GetRow() == start.GetRow()
+		}
+		switch {
+		case !ambiguous:
+		case !same_file:
+			res += string(rune('a' + start.GetCol()))
+		case !same_rank:
+			res += string(rune('1' + start.GetRow()))
+		default:
+			res += start.String()
+		}
+		if move.IsCapture() {
+			res += "x"
+		}
+		res += end.String()
+	}
+	return res + checkSuffix(move, board, bs)
+}
+
+// parsed parts of SAN, zero values mean the part is not given
+type sanParts struct {
+	piece   Piece // piece type
+	file    int8  // disambiguation, -1 if not given
+	row     int8  // disambiguation, -1 if not given
+	end     Position
+	promote Piece
+}
+
+func parseSANParts(san string) (sanParts, bool) {
+	res := sanParts{piece: Pawn, file: -1, row: -1}
+	s := san
+	if s != "" && strings.ContainsRune("NBRQKP", rune(s[0])) {
+		p, _ := MakePieceFromRune(rune(s[0]))
+		res.piece = p.GetType()
+		s = s[1:]
+	}
+
+	// promotion: =Q, Q or lower case variants
+	if n := len(s); n > 2 && strings.ContainsRune("NBRQnbrq", rune(s[n-1])) {
+		p, _ := MakePieceFromRune(rune(s[n-1]))
+		res.promote = p.GetType()
+		s = strings.TrimSuffix(s[:n-1], "=")
+	}
+
+	if len(s) < 2 {
+		return res, false
+	}
+	end, er := MakePiecePosFromFEN(s[len(s)-2:])
+	if er != nil {
+		return res, false
+	}
+	res.end = end
+	s = strings.TrimSuffix(s[:len(s)-2], "x")
+	s = strings.TrimSuffix(s, ":")
+
+	for _, c := range s {
+		switch {
+		case 'a' <= c && c <= 'h' && res.file < 0:
+			res.file = int8(c - 'a')
+		case '1' <= c && c <= '8' && res.row < 0:
+			res.row = int8(c - '1')
+		default:
+			return res, false
+		}
+	}
+	return res, true
+}
+
+// resolves SAN to the legal move, tolerates 0-0, missing or extra check suffixes,
+// omitted x, lower case promotion letters, e.p. and annotation glyphs
+func ParseSAN(san string, board *Board, bs BoardState) (Move, error) {
+	s := strings.TrimSpace(san)
+	s = strings.TrimSuffix(s, "e.p.")
+	s = strings.TrimRight(s, "+#!? ")
+
+	var buf [256]Move
+	legal := AppendLegalMoves(buf[:0], board, bs)
+
+	switch s {
+	case "O-O", "0-0", "o-o":
+		for _, m := range legal {
+			if m.IsKingCastle() {
+				return m, nil
+			}
+		}
+		return 0, &ErrorIllegalSAN{san}
+	case "O-O-O", "0-0-0", "o-o-o":
+		for _, m := range legal {
+			if m.IsQueenCastle() {
+				return m, nil
+			}
+		}
+		return 0, &ErrorIllegalSAN{san}
+	}
+
+	parts, ok := parseSANParts(s)
+	if !ok {
+		return 0, &ErrorMalformedMove{san}
+	}
+	var res Move
+	count := 0
+	for _, m := range legal {
+		start := m.GetStart()
+		if m.GetEnd() != parts.end || board.GetPiece(start).GetType() != parts.piece ||
+			m.GetPromote() != parts.promote ||
+			(parts.file >= 0 && start.GetCol() != parts.file) ||
+			(parts.row >= 0 && start.GetRow() != parts.row) {
+			continue
+		}
+		res = m
+		count++
+	}
+	switch count {
+	case 0:
+		return 0, &ErrorIllegalSAN{san}
+	case 1:
+		return res, nil
+	}
+	return 0, &ErrorAmbiguousSAN{san}
+}
@@ -0,0 +1,102 @@
+package main
+
+import "testing"
+
+func TestToSAN(t *testing.T) {
+	tests := []struct {
+		fen   string
+		start string
+		end   string
+		prom  Piece
+		san   string
+	}{
+		{initialFEN, "g1", "f3", NoPiece, "Nf3"},
+		{initialFEN, "e2", "e4", NoPiece, "e4"},
+		{"r1bqkb1r/pppp1ppp/2n2n2/4p2Q/2B1P3/8/PPPP1PPP/RNB1K1NR w KQkq - 4 4", "h5", "f7", NoPiece, "Qxf7#"},
+		{"rnbqkb1r/pppp1ppp/5n2/3Pp3/8/8/PPP1PPPP/RNBQKBNR w KQkq e6 0 3", "d5", "e6", NoPiece, "dxe6"},
+		{"rnbqkb1r/ppp1pppp/5n2/3p4/8/5N2/PPPPPPPP/RNBQKB1R b KQkq - 1 2", "b8", "d7", NoPiece, "Nbd7"},
+		{"rnbqkbnr/pppppppp/8/8/8/8/8/R3K2R w KQkq - 0 1", "e1", "c1", NoPiece, "O-O-O"},
+		{"rnbqkbnr/pppppppp/8/8/8/8/8/R3K2R w KQkq - 0 1", "e1", "g1", NoPiece, "O-O"},
+		{"3k4/4P3/8/8/8/8/8/4K3 w - - 0 1", "e7", "e8", Queen, "e8=Q+"},
+		{"4k3/8/8/8/8/8/4K3/R6R w - - 0 1", "a1", "d1", NoPiece, "Rad1"},
+		{"4k3/8/8/R7/8/8/8/R3K3 w - - 0 1", "a1", "a3", NoPiece, "R1a3"},
+		{"4k3/8/8/8/8/2Q1Q3/8/4Q2K w - - 0 1", "e3", "d2", NoPiece, "Qe3d2+"},
+	}
+	for _, test := range tests {
+		gp, er := ParseFEN(test.fen)
+		assert_er(er, t)
+		move := makeTestMove(test.start, test.end, test.prom)
+		if san := ToSAN(move, &gp.Board, gp.State); san != test.san {
+			t.Errorf("%s: got %s, expected %s", test.fen, san, test.san)
+		}
+	}
+}
+
+func TestToSANKeepsBoard(t *testing.T) {
+	gp, er := ParseFEN("r1bqkb1r/pppp1ppp/2n2n2/4p2Q/2B1P3/8/PPPP1PPP/RNB1K1NR w KQkq - 4 4")
+	assert_er(er, t)
+	board := gp.Board
+	ToSAN(makeTestMove("h5", "f7", NoPiece), &gp.Board, gp.State)
+	assert_equal(gp.Board, board, t)
+}
+
+func TestParseSAN(t *testing.T) {
+	tests := []struct {
+		fen string
+		san string
+		uci string
+	}{
+		{initialFEN, "Nf3", "g1f3"},
+		{initialFEN, "e4", "e2e4"},
+		{initialFEN, "e4!?", "e2e4"},
+		{"r1bqkb1r/pppp1ppp/2n2n2/4p2Q/2B1P3/8/PPPP1PPP/RNB1K1NR w KQkq - 4 4", "Qxf7", "h5f7"},
+		{"r1bqkb1r/pppp1ppp/2n2n2/4p2Q/2B1P3/8/PPPP1PPP/RNB1K1NR w KQkq - 4 4", "Qf7#", "h5f7"},
+		{"rnbqkb1r/pppp1ppp/5n2/3Pp3/8/8/PPP1PPPP/RNBQKBNR w KQkq e6 0 3", "dxe6 e.p.", "d5e6"},
+		{"rnbqkb1r/pppp1ppp/5n2/3Pp3/8/8/PPP1PPPP/RNBQKBNR w KQkq e6 0 3", "de6", "d5e6"},
+		{"rnbqkbnr/pppppppp/8/8/8/8/8/R3K2R w KQkq - 0 1", "0-0-0", "e1c1"},
+		{"rnbqkbnr/pppppppp/8/8/8/8/8/R3K2R w KQkq - 0 1", "O-O+", "e1g1"},
+		{"3k4/4P3/8/8/8/8/8/4K3 w - - 0 1", "e8=Q+", "e7e8q"},
+		{"3k4/4P3/8/8/8/8/8/4K3 w - - 0 1", "e8n", "e7e8n"},
+		{"3k4/4P3/8/8/8/8/8/4K3 w - - 0 1", "e8=b", "e7e8b"},
+		{"4k3/8/8/8/8/2Q1Q3/8/4Q2K w - - 0 1", "Qe3d2", "e3d2"},
+		{"4k3/8/8/R7/8/8/8/R3K3 w - - 0 1", "R1a3", "a1a3"},
+	}
+	for _, test := range tests {
+		gp, er := ParseFEN(test.fen)
+		assert_er(er, t)
+		move, er := ParseSAN(test.san, &gp.Board, gp.State)
+		assert_er(er, t)
+		assert_equal(move.UCI(), test.uci, t)
+	}
+}
+
+func TestParseSANErrors(t *testing.T) {
+	gp, er := ParseFEN("4k3/8/8/R7/8/8/8/R3K3 w - - 0 1")
+	assert_er(er, t)
+	if _, er := ParseSAN("Ra3", &gp.Board, gp.State); er == nil {
+		t.Errorf("ambiguous move was accepted")
+	} else if _, ok := er.(*ErrorAmbiguousSAN); !ok {
+		t.Errorf("unexpected error %v", er)
+	}
+	if _, er := ParseSAN("Nf3", &gp.Board, gp.State); er == nil {
+		t.Errorf("illegal move was accepted")
+	} else if _, ok := er.(*ErrorIllegalSAN); !ok {
+		t.Errorf("unexpected error %v", er)
+	}
+	if _, er := ParseSAN("Rz9", &gp.Board, gp.State); er == nil {
+		t.Errorf("malformed move was accepted")
+	} else if _, ok := er.(*ErrorMalformedMove); !ok {
+		t.Errorf("unexpected error %v", er)
+	}
+}
+
+func TestSANRoundTrip(t *testing.T) {
+	gp, er := ParseFEN(perftKiwipete)
+	assert_er(er, t)
+	for _, move := range GenerateLegalMoves(&gp.Board, gp.State) {
+		san := ToSAN(move, &gp.Board, gp.State)
+		parsed, er := ParseSAN(san, &gp.Board, gp.State)
+		assert_er(er, t)
+		assert_equal(parsed, move, t)
+	}
+}
@@ -0,0 +1,350 @@
+package main
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	MateScore = 30000 // score of the side which mates right now, mate in n plies scores MateScore - n
+	MaxPly    = 128
+	infScore  = MateScore + 1
+)
+
+// a zero field means no limit
+type SearchLimits struct {
+	Depth int
+	Nodes uint64
+	Time  time.Duration
+}
+
+// progress of the search reported after each completed depth
+type SearchInfo struct {
+	Depth int
+	Score int // centipawns from the point of view of the side to move
+	Nodes uint64
+	Time  time.Duration
+	PV    []Move
+}
+
+type SearchResult struct {
+	Move       Move // 0 if there is no legal move
+	Score      int
+	PV         []Move
+	Nodes      uint64
+	Iterations []SearchInfo // one per completed depth
+}
+
+// return true if the score means a forced mate for one of the sides
+func IsMateScore(score int) bool {
+	return score > MateScore-MaxPly || score < -MateScore+MaxPly
+}
+
+// number of moves to mate, negative if the side to move is mated
+func MateDistance(score int) int {
+	if score > 0 {
+		return (MateScore - score + 1) / 2
+	}
+	return -(MateScore + score) / 2
+}
+
+func inCheckBB(gp *GamePosition) bool {
+	is_white := gp.State.Get_Turn()
+	kings := gp.Bits.PieceSet(MakePiece(King, is_white))
+	return kings != 0 && attackersBB(&gp.Bits, kings.LSB(), gp.Bits.Occupied(), !is_white) != 0
+}
+
+// state of one search, the position is changed by make/unmake while searching
+type searcher struct {
+	ctx      context.Context
+	tt       *TranspositionTable // nil if the search has no table
+	limits   SearchLimits
+	deadline time.Time // zero if there is no time limit
+	gp       GamePosition
+	nodes    uint64
+	stopped  bool
+
+	// triangular PV table, pv[ply][ply:pv_len[ply]] is the best line found from ply
+	pv     [MaxPly][MaxPly]Move
+	pv_len [MaxPly]int
+
+	prev_pv   []Move // PV of the previous iteration, searched first
+	follow_pv bool   // the current line is the prefix of prev_pv
+
+	moves [MaxPly][256]Move
+}
+
+// checks the limits, the clock and the context are checked once per 1024 nodes
+func (s *searcher) checkStop() bool {
+	if s.stopped {
+		return true
+	}
+	if s.limits.Nodes > 0 && s.nodes >= s.limits.Nodes {
+		s.stopped = true
+	} else if s.nodes&1023 == 0 {
+		s.stopped = s.ctx.Err() != nil || !s.deadline.IsZero() && time.Now().After(s.deadline)
+	}
+	return s.stopped
+}
+
+// sorts moves by keys in descending order, move lists are short
+func sortMoves(moves []Move, keys []int) {
+	for i := 1; i < len(moves); i++ {
+		for j := i; j > 0 && keys[j] > keys[j-1]; j-- {
+			keys[j], keys[j-1] = keys[j-1], keys[j]
+			moves[j], moves[j-1] = moves[j-1], moves[j]
+		}
+	}
+}
+
+// sorts moves by the estimated strength: pv_move, the move from the transposition table, winning and equal captures and promotions
+// by SEE, quiet moves, losing captures
+func (s *searcher) orderMoves(moves []Move, pv_move Move, tt_move CompactMove) {
+	var keys [256]int
+	for i, move := range moves {
+		switch {
+		case move == pv_move:
+			keys[i] = 1 << 30
+		case tt_move != 0 && move.Compact() == tt_move:
+			keys[i] = 1 << 29
+		case move.IsCapture() || move.GetPromote() != NoPiece:
+			see := SEE(move, &s.gp.Board)
+			if see >= 0 {
+				keys[i] = 1<<20 + see
+			} else {
+				keys[i] = -1<<20 + see
+			}
+		}
+	}
+	sortMoves(moves, keys[:len(moves)])
+}
+
+func (s *searcher) negamax(depth, ply, alpha, beta int) int {
+	s.pv_len[ply] = ply
+	if s.checkStop() {
+		return 0
+	}
+	s.nodes++
+
+	if ply > 0 && s.gp.State.Get_HMoves() >= 100 {
+		return 0
+	}
+	in_check := inCheckBB(&s.gp)
+	if in_check { // check extension, so that mates are not hidden behind the horizon
+		depth++
+	}
+	if depth <= 0 {
+		return s.quiescence(ply, alpha, beta)
+	}
+	if ply >= MaxPly-1 {
+		return Evaluate(&s.gp)
+	}
+
+	key := s.gp.Hash()
+	var tt_move CompactMove
+	if s.tt != nil {
+		if entry, found := s.tt.Probe(key, ply); found {
+			tt_move = entry.Move
+			// an exact hit inside a PV window would cut the PV short, so PV nodes
+			// only take cutoffs that fall outside the window
+			pv_node := beta-alpha > 1
+			if ply > 0 && entry.Depth >= depth &&
+				(entry.Bound == TTBoundExact && !pv_node ||
+					entry.Bound != TTBoundUpper && entry.Score >= beta ||
+					entry.Bound != TTBoundLower && entry.Score <= alpha) {
+				return entry.Score
+			}
+		}
+	}
+
+	moves := AppendLegalMovesBB(s.moves[ply][:0], &s.gp)
+	if len(moves) == 0 {
+		if in_check {
+			return -MateScore + ply
+		}
+		return 0
+	}
+	var pv_move Move
+	if s.follow_pv && ply < len(s.prev_pv) {
+		pv_move = s.prev_pv[ply]
+	}
+	s.orderMoves(moves, pv_move, tt_move)
+
+	alpha_start := alpha
+	best := -infScore
+	var best_move Move
+	for i, move := range moves {
+		s.follow_pv = s.follow_pv && i == 0 && move == pv_move
+		undo := s.gp.MakeMove(move)
+		score := -s.negamax(depth-1, ply+1, -beta, -alpha)
+		s.gp.UnmakeMove(move, undo)
+		if s.stopped {
+			return 0
+		}
+		if score > best {
+			best, best_move = score, move
+		}
+		if score > alpha {
+			alpha = score
+			s.pv[ply][ply] = move
+			copy(s.pv[ply][ply+1:], s.pv[ply+1][ply+1:s.pv_len[ply+1]])
+			s.pv_len[ply] = s.pv_len[ply+1]
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+
+	if s.tt != nil {
+		bound := TTBoundExact
+		switch {
+		case best <= alpha_start:
+			bound = TTBoundUpper
+			best_move = 0 // all moves failed low, none of them is known to be the best
+		case best >= beta:
+			bound = TTBoundLower
+		}
+		s.tt.Store(key, best_move, best, depth, ply, bound)
+	}
+	return best
+}
+
+// search of captures and queen promotions until the position is quiet, the side to move
+// can stand pat with the static evaluation unless it is in check, then all evasions are searched
+func (s *searcher) quiescence(ply, alpha, beta int) int {
+	s.pv_len[ply] = ply
+	if s.checkStop() {
+		return 0
+	}
+	s.nodes++
+
+	in_check := inCheckBB(&s.gp)
+	if ply >= MaxPly-1 {
+		return Evaluate(&s.gp)
+	}
+	best := -infScore
+	if !in_check {
+		best = Evaluate(&s.gp)
+		if best >= beta {
+			return best
+		}
+		alpha = max(alpha, best)
+	}
+
+	moves := AppendLegalMovesBB(s.moves[ply][:0], &s.gp)
+	if len(moves) == 0 {
+		if in_check {
+			return -MateScore + ply
+		}
+		return best // stalemate is ignored like other quiet moves
+	}
+	if in_check {
+		s.orderMoves(moves, 0, 0)
+	} else {
+		// captures and queen promotions which do not lose material, the best exchange first
+		var keys [256]int
+		n := 0
+		for _, move := range moves {
+			if !move.IsCapture() && move.GetPromote().GetType() != Queen {
+				continue
+			}
+			see := SEE(move, &s.gp.Board)
+			if see < 0 {
+				continue
+			}
+			moves[n], keys[n] = move, see
+			n++
+		}
+		moves = moves[:n]
+		sortMoves(moves, keys[:n])
+	}
+
+	for _, move := range moves {
+		undo := s.gp.MakeMove(move)
+		score := -s.quiescence(ply+1, -beta, -alpha)
+		s.gp.UnmakeMove(move, undo)
+		if s.stopped {
+			return 0
+		}
+		if score > best {
+			best = score
+		}
+		if score > alpha {
+			alpha = score
+			s.pv[ply][ply] = move
+			copy(s.pv[ply][ply+1:], s.pv[ply+1][ply+1:s.pv_len[ply+1]])
+			s.pv_len[ply] = s.pv_len[ply+1]
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+	return best
+}
+
+// negamax alpha-beta with iterative deepening, report is called after each completed depth,
+// the search stops on the limits or the cancellation of ctx and returns the result of the last
+// completed depth, tt can be nil or shared by consecutive searches of a game
+func Search(ctx context.Context, gp GamePosition, tt *TranspositionTable, limits SearchLimits, report func(SearchInfo)) SearchResult {
+	start_time := time.Now()
+	s := &searcher{ctx: ctx, tt: tt, limits: limits, gp: gp}
+	if tt != nil {
+		tt.NewSearch()
+	}
+	if limits.Time > 0 {
+		s.deadline = start_time.Add(limits.Time)
+	}
+
+	var res SearchResult
+	root_moves := GenerateLegalMovesBB(&gp)
+	if len(root_moves) == 0 {
+		if inCheckBB(&gp) {
+			res.Score = -MateScore
+		}
+		return res
+	}
+	res.Move = root_moves[0] // in case the first depth is not completed
+
+	max_depth := MaxPly - 1
+	if limits.Depth > 0 {
+		max_depth = min(limits.Depth, max_depth)
+	}
+	for depth := 1; depth <= max_depth; depth++ {
+		s.follow_pv = true
+		score := s.negamax(depth, 0, -infScore, infScore)
+		if s.stopped {
+			// the best move of the interrupted depth is not worse than the previous one
+			if depth == 1 && s.pv_len[0] > 0 {
+				res.Move = s.pv[0][0]
+				res.PV = []Move{res.Move}
+			}
+			break
+		}
+		pv := append([]Move(nil), s.pv[0][:s.pv_len[0]]...)
+		s.prev_pv = pv
+		res.Move, res.Score, res.PV = pv[0], score, pv
+		info := SearchInfo{Depth: depth, Score: score, Nodes: s.nodes, Time: time.Since(start_time), PV: pv}
+		res.Iterations = append(res.Iterations, info)
+		if report != nil {
+			report(info)
+		}
+		// deeper search can not find a shorter mate
+		if IsMateScore(score) && MateScore-max(score, -score) <= depth {
+			break
+		}
+	}
+	res.Nodes = s.nodes
+	return res
+}
+
+// time for the next move from the remaining time, increment and moves to the time control,
+// moves_to_go is 0 for sudden death
+func AllocateTime(time_left, inc time.Duration, moves_to_go int) time.Duration {
+	const overhead = 50 * time.Millisecond
+	if moves_to_go <= 0 {
+		moves_to_go = 30
+	}
+	res := time_left/time.Duration(moves_to_go) + inc*3/4
+	res = min(res, time_left-overhead)
+	return max(res, 10*time.Millisecond)
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func makeTestGamePosition(fen string, t *testing.T) GamePosition {
+	gp, er := ParseFEN(fen)
+	assert_er(er, t)
+	return gp
+}
+
+func searchTestPosition(fen string, limits SearchLimits, t *testing.T) SearchResult {
+	gp := makeTestGamePosition(fen, t)
+	return Search(context.Background(), gp, nil, limits, nil)
+}
+
+func TestSearchMate(t *testing.T) {
+	res := searchTestPosition("6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1", SearchLimits{Depth: 1}, t)
+	assert_equal(res.Move.UCI(), "a1a8", t)
+	assert_equal(res.Score, MateScore-1, t)
+	assert_equal(MateDistance(res.Score), 1, t)
+
+	res = searchTestPosition("7k/8/8/8/8/8/R7/1R5K w - - 0 1", SearchLimits{Depth: 5}, t)
+	assert_equal(res.Score, MateScore-3, t)
+	assert_equal(MateDistance(res.Score), 2, t)
+	assert_equal(len(res.PV), 3, t)
+	assert_equal(len(res.Iterations), 3, t) // the search stops once the mate is proven
+
+	res = searchTestPosition("7k/1R6/8/8/8/8/R7/7K b - - 0 1", SearchLimits{Depth: 3}, t)
+	assert_equal(res.Move.UCI(), "h8g8", t)
+	assert_equal(res.Score, -MateScore+2, t)
+	assert_equal(MateDistance(res.Score), -1, t)
+}
+
+func TestSearchPrefersFasterMate(t *testing.T) {
+	// Qg7 mates at once, the rook lift mates later
+	res := searchTestPosition("7k/8/5KQ1/8/8/8/8/R7 w - - 0 1", SearchLimits{Depth: 4}, t)
+	assert_equal(res.Score, MateScore-1, t)
+}
+
+func TestSearchWinsMaterial(t *testing.T) {
+	res := searchTestPosition("4k3/8/8/3q4/8/8/8/3RK3 w - - 0 1", SearchLimits{Depth: 2}, t)
+	assert_equal(res.Move.UCI(), "d1d5", t)
+	assert_equal(res.Score, pieceValues[Rook], t)
+}
+
+func TestSearchNoMoves(t *testing.T) {
+	res := searchTestPosition("7k/5Q2/6K1/8/8/8/8/8 b - - 0 1", SearchLimits{Depth: 3}, t)
+	assert_equal(res.Move, Move(0), t)
+	assert_equal(res.Score, 0, t)
+
+	res = searchTestPosition("R6k/8/6K1/8/8/8/8/8 b - - 0 1", SearchLimits{Depth: 3}, t)
+	assert_equal(res.Move, Move(0), t)
+	assert_equal(res.Score, -MateScore, t)
+}
+
+func TestSearchLimits(t *testing.T) {
+	gp := makeTestGamePosition(perftKiwipete, t)
+	res := Search(context.Background(), gp, nil, SearchLimits{Depth: 3}, nil)
+	assert_equal(len(res.Iterations), 3, t)
+	root_moves := GenerateLegalMovesBB(&gp)
+	for i, info := range res.Iterations {
+		assert_equal(info.Depth, i+1, t)
+		assert_equal(containsMove(root_moves, info.PV[0]), true, t)
+	}
+	assert_equal(res.Move, res.Iterations[len(res.Iterations)-1].PV[0], t)
+
+	res = searchTestPosition(perftKiwipete, SearchLimits{Nodes: 5000}, t)
+	if res.Nodes > 5000 {
+		t.Errorf("node limit exceeded: %d", res.Nodes)
+	}
+	assert_equal(res.Move != 0, true, t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	res = Search(ctx, gp, nil, SearchLimits{}, nil)
+	assert_equal(res.Move != 0, true, t)
+	assert_equal(containsMove(root_moves, res.Move), true, t)
+}
+
+func TestSearchKeepsPosition(t *testing.T) {
+	gp := makeTestGamePosition(perftKiwipete, t)
+	before := gp
+	var reported []SearchInfo
+	res := Search(context.Background(), gp, nil, SearchLimits{Depth: 2}, func(info SearchInfo) {
+		reported = append(reported, info)
+	})
+	assert_equal(gp.String(), before.String(), t)
+	assert_equal(len(reported), len(res.Iterations), t)
+}
+
+func TestQuiescenceHorizon(t *testing.T) {
+	// the pawn on d5 is defended, taking it loses the queen right after the horizon
+	res := searchTestPosition("4k3/8/2p5/3p4/8/8/8/3QK3 w - - 0 1", SearchLimits{Depth: 1}, t)
+	if res.Move.UCI() == "d1d5" {
+		t.Errorf("queen takes defended pawn")
+	}
+	assert_equal(res.Score, 900-200, t)
+
+	// the exchange on d5 wins a knight
+	res = searchTestPosition("4k3/8/8/3n4/8/8/3R4/3RK3 w - - 0 1", SearchLimits{Depth: 1}, t)
+	assert_equal(res.Move.UCI(), "d2d5", t)
+	assert_equal(res.Score, 1000, t)
+}
+
+func TestQuiescenceInCheck(t *testing.T) {
+	s := &searcher{ctx: context.Background(), gp: makeTestGamePosition("R5k1/5ppp/8/8/8/8/8/6K1 b - - 0 1", t)}
+	assert_equal(s.quiescence(0, -infScore, infScore), -MateScore, t)
+
+	// the only evasion loses the queen
+	s = &searcher{ctx: context.Background(), gp: makeTestGamePosition("4k3/8/8/8/8/8/2n5/Q3K3 w - - 0 1", t)}
+	assert_equal(s.quiescence(0, -infScore, infScore) < 0, true, t)
+}
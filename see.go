@@ -0,0 +1,72 @@
+package main
+
+import "math/bits"
+
+// piece values of the exchange, the king can capture only as the last piece
+var seeValues = [King + 1]int{
+	Pawn:   100,
+	Knight: 320,
+	Bishop: 330,
+	Rook:   500,
+	Queen:  900,
+	King:   20000,
+}
+
+// square of the least valuable piece from the attackers set
+func leastValuableAttacker(set uint64, board *Board) (Position, bool) {
+	var res Position
+	best := 0
+	for set != 0 {
+		pos := Position(bits.TrailingZeros64(set))
+		set &= set - 1
+		if value := seeValues[board.GetPiece(pos).GetType()]; best == 0 || value < best {
+			res, best = pos, value
+		}
+	}
+	return res, best != 0
+}
+
+// static exchange evaluation: material gain in centipawns of the side making the move
+// when both sides keep capturing on the target square with their least valuable pieces,
+// x-ray attackers are found by removing the pieces which have already captured
+func SEE(move Move, board *Board) int {
+	b := *board
+	start := move.GetStart()
+	target := move.GetEnd()
+	attacker := b.GetPiece(start)
+
+	var gain [32]int
+	if move.IsEnPassant() {
+		gain[0] = seeValues[Pawn]
+		b.SetPiece(MakePos(start.GetRow(), target.GetCol()), NoPiece)
+	} else {
+		gain[0] = seeValues[b.GetPiece(target).GetType()]
+	}
+	on_target := seeValues[attacker.GetType()]
+	if promote := move.GetPromote(); promote != NoPiece {
+		gain[0] += seeValues[promote.GetType()] - seeValues[Pawn]
+		on_target = seeValues[promote.GetType()]
+	}
+	b.SetPiece(start, NoPiece)
+	b.SetPiece(target, attacker)
+
+	is_white := !attacker.IsWhite()
+	d := 0
+	for d+1 < len(gain) {
+		pos, found := leastValuableAttacker(Attackers(target, &b, is_white), &b)
+		if !found {
+			break
+		}
+		d++
+		gain[d] = on_target - gain[d-1]
+		on_target = seeValues[b.GetPiece(pos).GetType()]
+		b.SetPiece(target, b.GetPiece(pos))
+		b.SetPiece(pos, NoPiece)
+		is_white = !is_white
+	}
+	// each side may stop capturing when it is not profitable
+	for ; d > 0; d-- {
+		gain[d-1] = -max(-gain[d-1], gain[d])
+	}
+	return gain[0]
+}
@@ -0,0 +1,47 @@
+package main
+
+import "testing"
+
+func TestSEE(t *testing.T) {
+	tests := []struct {
+		fen      string
+		start    string
+		end      string
+		expected int
+	}{
+		// undefended pawn
+		{"1k1r4/1pp4p/p7/4p3/8/P5P1/1PP4P/2K1R3 w - - 0 1", "e1", "e5", 100},
+		// knight lost for pawn after the exchange on e5
+		{"1k1r3q/1ppn3p/p4b2/4p3/8/P2N2P1/1PP1R1BP/2K1Q3 w - - 0 1", "d3", "e5", 100 - 320},
+		// pawn defended piece
+		{"4k3/8/2p5/3n4/8/8/8/3QK3 w - - 0 1", "d1", "d5", 320 - 900},
+		{"4k3/8/8/3n4/8/8/8/3QK3 w - - 0 1", "d1", "d5", 320},
+		// rook battery, the second rook x-rays through the first one
+		{"6k1/3r4/8/3p4/8/8/3R4/3RK3 w - - 0 1", "d2", "d5", 100},
+		{"3r2k1/3r4/8/3p4/8/8/3R4/3RK3 w - - 0 1", "d2", "d5", 100 - 500},
+		// queen behind the bishop
+		{"4k3/8/5p2/4p3/3B4/2Q5/8/4K3 w - - 0 1", "d4", "e5", 100 - 330 + 100},
+		// king can not recapture a defended piece
+		{"4k3/4r3/8/8/8/8/4p3/4K3 w - - 0 1", "e1", "e2", -20000 + 100},
+		{"4k3/8/8/8/8/8/4p3/4K3 w - - 0 1", "e1", "e2", 100},
+		// en passant
+		{"4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1", "e5", "d6", 100},
+		// quiet move to an attacked square
+		{"4k3/8/8/4p3/8/5N2/8/4K3 w - - 0 1", "f3", "d4", -320},
+	}
+	for _, test := range tests {
+		gp := makeTestGamePosition(test.fen, t)
+		move := AddMoveFlags(makeTestMove(test.start, test.end, NoPiece), &gp.Board, gp.State)
+		if see := SEE(move, &gp.Board); see != test.expected {
+			t.Errorf("%s %s: SEE %d, expected %d", test.fen, move, see, test.expected)
+		}
+	}
+}
+
+func TestSEEPromotion(t *testing.T) {
+	gp := makeTestGamePosition("1r2k3/P7/8/8/8/8/8/4K3 w - - 0 1", t)
+	move := AddMoveFlags(makeTestMove("a7", "a8", Queen), &gp.Board, gp.State)
+	assert_equal(SEE(move, &gp.Board), 900-100-900, t)
+	move = AddMoveFlags(makeTestMove("a7", "b8", Queen), &gp.Board, gp.State)
+	assert_equal(SEE(move, &gp.Board), 500+900-100, t)
+}
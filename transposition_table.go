@@ -0,0 +1,171 @@
+package main
+
+import (
+	"math/bits"
+	"unsafe"
+)
+
+type TTBound uint8
+
+const (
+	TTBoundNone  TTBound = iota // empty entry
+	TTBoundExact                // score is exact
+	TTBoundLower                // search failed high, score is a lower bound
+	TTBoundUpper                // search failed low, score is an upper bound
+)
+
+const (
+	DefaultTTSizeMB = 16
+	maxTTSizeMB     = 1 << 16
+	ttBucketSize    = 4
+	ttGenerationMax = 1 << 6
+)
+
+type ttEntry struct {
+	key       uint64
+	move      CompactMove
+	score     int16
+	depth     int8
+	gen_bound uint8 // generation << 2 | bound
+}
+
+func (e *ttEntry) bound() TTBound {
+	return TTBound(e.gen_bound & 3)
+}
+
+func (e *ttEntry) generation() uint8 {
+	return e.gen_bound >> 2
+}
+
+// entries of the same index, 64 bytes fill one cache line
+type ttBucket [ttBucketSize]ttEntry
+
+// result of the table probe, Score is relative to the probed position
+type TTEntry struct {
+	Move  CompactMove
+	Score int
+	Depth int
+	Bound TTBound
+}
+
+// transposition table keyed by the Zobrist hash, it is kept between searches of a game
+type TranspositionTable struct {
+	buckets    []ttBucket
+	generation uint8 // incremented by NewSearch, entries of older searches are replaced first
+}
+
+func MakeTranspositionTable(size_mb int) *TranspositionTable {
+	tt := &TranspositionTable{}
+	tt.Resize(size_mb)
+	return tt
+}
+
+// reallocates the table with the size in megabytes, entries are lost
+func (tt *TranspositionTable) Resize(size_mb int) {
+	n := max(size_mb, 1) << 20 / int(unsafe.Sizeof(ttBucket{}))
+	tt.buckets = make([]ttBucket, n)
+	tt.generation = 0
+}
+
+func (tt *TranspositionTable) Clear() {
+	clear(tt.buckets)
+	tt.generation = 0
+}
+
+// marks the start of a new search, entries of previous searches become older
+func (tt *TranspositionTable) NewSearch() {
+	tt.generation = (tt.generation + 1) % ttGenerationMax
+}
+
+func (tt *TranspositionTable) bucket(key uint64) *ttBucket {
+	index, _ := bits.Mul64(key, uint64(len(tt.buckets)))
+	return &tt.buckets[index]
+}
+
+// mate scores are stored as the distance from the stored position, not from the root
+func scoreToTT(score, ply int) int {
+	switch {
+	case score > MateScore-MaxPly:
+		return score + ply
+	case score < -MateScore+MaxPly:
+		return score - ply
+	}
+	return score
+}
+
+func scoreFromTT(score, ply int) int {
+	switch {
+	case score > MateScore-MaxPly:
+		return score - ply
+	case score < -MateScore+MaxPly:
+		return score + ply
+	}
+	return score
+}
+
+// finds the entry of the position, ply is the distance from the root for mate scores
+func (tt *TranspositionTable) Probe(key uint64, ply int) (TTEntry, bool) {
+	b := tt.bucket(key)
+	for i := range b {
+		e := &b[i]
+		if e.key == key && e.bound() != TTBoundNone {
+			e.gen_bound = tt.generation<<2 | uint8(e.bound()) // refresh the entry used again
+			return TTEntry{e.move, scoreFromTT(int(e.score), ply), int(e.depth), e.bound()}, true
+		}
+	}
+	return TTEntry{}, false
+}
+
+func (tt *TranspositionTable) replacedEntry(b *ttBucket, key uint64) *ttEntry {
+	for i := range b {
+		if b[i].key == key && b[i].bound() != TTBoundNone {
+			return &b[i]
+		}
+	}
+	victim := &b[0]
+	victim_worth := 1 << 30
+	for i := range b {
+		e := &b[i]
+		if e.bound() == TTBoundNone {
+			return e
+		}
+		age := int((tt.generation - e.generation()) % ttGenerationMax)
+		if worth := int(e.depth) - 8*age; worth < victim_worth {
+			victim, victim_worth = e, worth
+		}
+	}
+	return victim
+}
+
+// stores the search result, the entry of the same position is replaced, otherwise the
+// empty entry or the one with the lowest depth and the oldest generation is replaced
+func (tt *TranspositionTable) Store(key uint64, move Move, score, depth, ply int, bound TTBound) {
+	b := tt.bucket(key)
+	victim := tt.replacedEntry(b, key)
+	compact := move.Compact()
+	if move == 0 && victim.key == key {
+		compact = victim.move // keep the best move of the previous search of the position
+	}
+	*victim = ttEntry{
+		key:       key,
+		move:      compact,
+		score:     int16(scoreToTT(score, ply)),
+		depth:     int8(min(max(depth, -128), 127)),
+		gen_bound: tt.generation<<2 | uint8(bound),
+	}
+}
+
+// permille of the table used by the current search, estimated from the first 1000 entries
+func (tt *TranspositionTable) Hashfull() int {
+	count, total := 0, 0
+	for i := 0; i < len(tt.buckets) && total < 1000; i++ {
+		for j := range tt.buckets[i] {
+			e := &tt.buckets[i][j]
+			if e.bound() != TTBoundNone && e.generation() == tt.generation {
+				count++
+			}
+			total++
+		}
+	}
+	return count * 1000 / max(total, 1)
+}
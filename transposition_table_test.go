@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+func TestTTBucketSize(t *testing.T) {
+	assert_equal(unsafe.Sizeof(ttBucket{}), uintptr(64), t)
+	tt := MakeTranspositionTable(1)
+	assert_equal(len(tt.buckets), 1<<20/64, t)
+}
+
+func TestTTStoreProbe(t *testing.T) {
+	tt := MakeTranspositionTable(1)
+	move := makeTestMove("e7", "e8", Queen)
+	_, found := tt.Probe(42, 0)
+	assert_equal(found, false, t)
+
+	tt.Store(42, move, 123, 5, 3, TTBoundLower)
+	entry, found := tt.Probe(42, 7)
+	assert_equal(found, true, t)
+	assert_equal(entry, TTEntry{move.Compact(), 123, 5, TTBoundLower}, t)
+
+	// mate in 2 plies from the stored position is found 3 plies from the root
+	tt.Store(42, 0, MateScore-5, 6, 3, TTBoundExact)
+	entry, _ = tt.Probe(42, 1)
+	assert_equal(entry.Score, MateScore-3, t)
+	assert_equal(entry.Move, move.Compact(), t) // the move of the previous store is kept
+	tt.Store(42, 0, -MateScore+5, 6, 3, TTBoundExact)
+	entry, _ = tt.Probe(42, 1)
+	assert_equal(entry.Score, -MateScore+3, t)
+
+	tt.Clear()
+	_, found = tt.Probe(42, 0)
+	assert_equal(found, false, t)
+}
+
+func TestTTReplacement(t *testing.T) {
+	tt := MakeTranspositionTable(1)
+	const base uint64 = 1 << 63 // keys base+i share the bucket
+	for i := range ttBucketSize {
+		tt.Store(base+uint64(i), 0, 0, 10-i, 0, TTBoundExact)
+	}
+	tt.Store(base+100, 0, 0, 1, 0, TTBoundExact)
+	_, found := tt.Probe(base+uint64(ttBucketSize-1), 0)
+	assert_equal(found, false, t) // the shallowest entry is replaced
+	_, found = tt.Probe(base+100, 0)
+	assert_equal(found, true, t)
+
+	// the shallow entry of the old search gives way
+	tt.NewSearch()
+	tt.NewSearch()
+	tt.Probe(base+1, 0) // used again in the current search
+	tt.Store(base+200, 0, 0, 1, 0, TTBoundExact)
+	for _, key := range []uint64{base, base + 1, base + 2} {
+		_, found = tt.Probe(key, 0)
+		assert_equal(found, true, t)
+	}
+	_, found = tt.Probe(base+100, 0)
+	assert_equal(found, false, t)
+}
+
+func TestTTHashfull(t *testing.T) {
+	tt := MakeTranspositionTable(1)
+	assert_equal(tt.Hashfull(), 0, t)
+	n := uint64(len(tt.buckets))
+	for i := uint64(0); i < 125; i++ {
+		key := i * (^uint64(0)/n + 1) // the first key of the bucket i
+		tt.Store(key+1, 0, 0, 1, 0, TTBoundExact)
+		tt.Store(key+2, 0, 0, 1, 0, TTBoundExact)
+	}
+	assert_equal(tt.Hashfull(), 250, t)
+	tt.NewSearch()
+	assert_equal(tt.Hashfull(), 0, t)
+}
+
+func TestSearchWithTT(t *testing.T) {
+	tt := MakeTranspositionTable(1)
+	gp := makeTestGamePosition("7k/8/8/8/8/8/R7/1R5K w - - 0 1", t)
+	res := Search(context.Background(), gp, tt, SearchLimits{Depth: 5}, nil)
+	assert_equal(res.Score, MateScore-3, t)
+	assert_equal(len(res.PV), 3, t)
+
+	gp = makeTestGamePosition(perftKiwipete, t)
+	without := Search(context.Background(), gp, nil, SearchLimits{Depth: 4}, nil)
+	with := Search(context.Background(), gp, tt, SearchLimits{Depth: 4}, nil)
+	if with.Nodes >= without.Nodes {
+		t.Errorf("table does not reduce the search: %d nodes, %d without it", with.Nodes, without.Nodes)
+	}
+	entry, found := tt.Probe(gp.Hash(), 0)
+	assert_equal(found, true, t)
+	assert_equal(entry.Move, with.Move.Compact(), t)
+	assert_equal(tt.Hashfull() > 0, true, t)
+}
+
+func TestSearchWarmTTKeepsPV(t *testing.T) {
+	tt := MakeTranspositionTable(1)
+	gp := makeTestGamePosition(perftKiwipete, t)
+	first := Search(context.Background(), gp, tt, SearchLimits{Depth: 4}, nil)
+	tt.NewSearch()
+	second := Search(context.Background(), gp, tt, SearchLimits{Depth: 4}, nil)
+	assert_equal(len(first.PV), 4, t)
+	assert_equal(len(second.PV), len(first.PV), t)
+}
+
+func TestUCIHashOption(t *testing.T) {
+	var sb strings.Builder
+	e := MakeUCIEngine(&sb)
+	e.Handle("uci")
+	assert_contains(sb.String(), "option name Hash type spin default 16 ", t)
+	e.Handle("setoption name Hash value 2")
+	assert_equal(len(e.tt.buckets), 2<<20/64, t)
+	e.Handle("setoption name Hash value 0")
+	assert_contains(sb.String(), "info string bad Hash value 0", t)
+	e.Handle("go depth 2")
+	e.waitSearch()
+	assert_contains(sb.String(), " hashfull ", t)
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	engineName   = "enginsant"
+	engineAuthor = "the enginsant authors"
+)
+
+// search running in the background
+type uciSearch struct {
+	cancel      context.CancelFunc
+	done        chan struct{}
+	ponderhit   chan struct{} // closed by ponderhit, nil if the search is not pondering
+	ponder_time time.Duration // time limit which starts at ponderhit
+}
+
+// state of the UCI session, commands are handled one by one by Handle
+type UCIEngine struct {
+	out    io.Writer
+	out_mu sync.Mutex // search goroutine writes info and bestmove concurrently with commands
+	game   *Game
+	search *uciSearch
+	tt     *TranspositionTable // used by the search goroutine, changed only when no search runs
+}
+
+func MakeUCIEngine(out io.Writer) *UCIEngine {
+	return &UCIEngine{out: out, game: NewGame(), tt: MakeTranspositionTable(DefaultTTSizeMB)}
+}
+
+func (e *UCIEngine) printf(format string, args ...any) {
+	e.out_mu.Lock()
+	defer e.out_mu.Unlock()
+	fmt.Fprintf(e.out, format+"\n", args...)
+}
+
+// handles one command, returns true on quit
+func (e *UCIEngine) Handle(line string) bool {
+	tokens := strings.Fields(line)
+	if len(tokens) == 0 {
+		return false
+	}
+	switch tokens[0] {
+	case "uci":
+		e.printf("id name %s", engineName)
+		e.printf("id author %s", engineAuthor)
+		e.printf("option name Hash type spin default %d min 1 max %d", DefaultTTSizeMB, maxTTSizeMB)
+		e.printf("option name Ponder type check default false")
+		e.printf("uciok")
+	case "isready":
+		e.printf("readyok")
+	case "setoption":
+		e.setOption(tokens[1:])
+	case "ucinewgame":
+		e.stopSearch()
+		e.game = NewGame()
+		e.tt.Clear()
+	case "position":
+		e.stopSearch()
+		if er := e.setPosition(tokens[1:]); er != nil {
+			e.printf("info string %s", er.Error())
+		}
+	case "go":
+		e.stopSearch()
+		e.startSearch(tokens[1:])
+	case "stop":
+		e.stopSearch()
+	case "ponderhit":
+		e.ponderHit()
+	case "quit":
+		e.stopSearch()
+		return true
+	case "debug":
+	default:
+		e.printf("info string unknown command %s", tokens[0])
+	}
+	return false
+}
+
+// setoption name <name> [value <value>], the name can contain spaces
+func (e *UCIEngine) setOption(args []string) {
+	var name, value []string
+	target := &name
+	for _, arg := range args {
+		switch arg {
+		case "name":
+			target = &name
+		case "value":
+			target = &value
+		default:
+			*target = append(*target, arg)
+		}
+	}
+	switch strings.ToLower(strings.Join(name, " ")) {
+	case "hash":
+		size_mb, er := strconv.Atoi(strings.Join(value, " "))
+		if er != nil || size_mb < 1 || size_mb > maxTTSizeMB {
+			e.printf("info string bad Hash value %s", strings.Join(value, " "))
+			return
+		}
+		e.stopSearch()
+		e.tt.Resize(size_mb)
+	case "ponder": // pondering is controlled by go ponder
+	default:
+		e.printf("info string unknown option %s", strings.Join(name, " "))
+	}
+}
+
+// position startpos|fen <FEN> [moves <move>...]
+func (e *UCIEngine) setPosition(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("position without arguments")
+	}
+	moves_at := len(args)
+	for i, arg := range args {
+		if arg == "moves" {
+			moves_at = i
+			break
+		}
+	}
+
+	var start GamePosition
+	switch args[0] {
+	case "startpos":
+		start = MakeInitialGamePosition()
+	case "fen":
+		var er error
+		start, er = ParseFEN(strings.Join(args[1:moves_at], " "))
+		if er != nil {
+			return er
+		}
+	default:
+		return fmt.Errorf("unknown position type %s", args[0])
+	}
+
+	game := MakeGame(start)
+	for i := moves_at + 1; i < len(args); i++ {
+		move, er := ParseUCIMove(args[i], &game.Position.Board, game.Position.State)
+		if er != nil {
+			return er
+		}
+		if er := game.Play(move); er != nil {
+			return er
+		}
+	}
+	e.game = game
+	return nil
+}
+
+func (e *UCIEngine) startSearch(args []string) {
+	var limits SearchLimits
+	var infinite, ponder bool
+	var move_time, time_left, inc time.Duration
+	var moves_to_go int
+	is_white := e.game.Position.State.Get_Turn()
+	for i := 0; i < len(args); i++ {
+		var value int
+		switch args[i] {
+		case "infinite":
+			infinite = true
+			continue
+		case "ponder":
+			ponder = true
+			continue
+		case "depth", "nodes", "movetime", "wtime", "btime", "winc", "binc", "movestogo", "mate":
+			if i+1 < len(args) {
+				value, _ = strconv.Atoi(args[i+1])
+			}
+		default:
+			continue
+		}
+		ms := time.Duration(value) * time.Millisecond
+		switch args[i] {
+		case "depth":
+			limits.Depth = value
+		case "nodes":
+			limits.Nodes = uint64(max(value, 0))
+		case "movetime":
+			move_time = ms
+		case "wtime", "btime":
+			if (args[i] == "wtime") == is_white {
+				time_left = ms
+			}
+		case "winc", "binc":
+			if (args[i] == "winc") == is_white {
+				inc = ms
+			}
+		case "movestogo":
+			moves_to_go = value
+		case "mate":
+			// a mate in n moves is found within 2n-1 plies
+			if value > 0 {
+				limits.Depth = 2*value - 1
+			}
+		}
+		i++
+	}
+
+	switch {
+	case infinite:
+	case move_time > 0:
+		limits.Time = move_time
+	case time_left > 0:
+		limits.Time = AllocateTime(time_left, inc, moves_to_go)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	search := &uciSearch{cancel: cancel, done: make(chan struct{})}
+	if ponder {
+		search.ponderhit = make(chan struct{})
+		search.ponder_time = limits.Time
+		limits.Time = 0
+	}
+	e.search = search
+	gp := e.game.Position
+	ponderhit := search.ponderhit
+	go func() {
+		defer close(search.done)
+		res := Search(ctx, gp, e.tt, limits, e.printInfo)
+		// bestmove of infinite or ponder search is sent only after stop or ponderhit
+		if infinite || ponder {
+			select {
+			case <-ctx.Done():
+			case <-ponderhit:
+			}
+		}
+		e.printBestMove(res)
+	}()
+}
+
+// cancels the running search and waits until it sends bestmove
+func (e *UCIEngine) stopSearch() {
+	if e.search == nil {
+		return
+	}
+	e.search.cancel()
+	<-e.search.done
+	e.search = nil
+}
+
+// waits until the running search finishes by itself
+func (e *UCIEngine) waitSearch() {
+	if e.search != nil {
+		<-e.search.done
+	}
+}
+
+// the opponent played the expected move, the ponder search continues as a normal one
+func (e *UCIEngine) ponderHit() {
+	search := e.search
+	if search == nil || search.ponderhit == nil {
+		return
+	}
+	if search.ponder_time > 0 {
+		time.AfterFunc(search.ponder_time, search.cancel)
+	}
+	close(search.ponderhit)
+	search.ponderhit = nil
+}
+
+func uciScore(score int) string {
+	if IsMateScore(score) {
+		return fmt.Sprintf("mate %d", MateDistance(score))
+	}
+	return fmt.Sprintf("cp %d", score)
+}
+
+func (e *UCIEngine) printInfo(info SearchInfo) {
+	ms := info.Time.Milliseconds()
+	nps := info.Nodes * 1000 / uint64(max(ms, 1))
+	pv := make([]string, len(info.PV))
+	for i, move := range info.PV {
+		pv[i] = move.UCI()
+	}
+	e.printf("info depth %d score %s nodes %d nps %d hashfull %d time %d pv %s",
+		info.Depth, uciScore(info.Score), info.Nodes, nps, e.tt.Hashfull(), ms, strings.Join(pv, " "))
+}
+
+func (e *UCIEngine) printBestMove(res SearchResult) {
+	switch {
+	case res.Move == 0:
+		e.printf("bestmove 0000")
+	case len(res.PV) > 1:
+		e.printf("bestmove %s ponder %s", res.Move.UCI(), res.PV[1].UCI())
+	default:
+		e.printf("bestmove %s", res.Move.UCI())
+	}
+}
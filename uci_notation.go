@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+type ErrorMalformedMove struct {
+	move string
+}
+
+func (e *ErrorMalformedMove) Error() string {
+	return fmt.Sprintf("malformed move: %q", e.move)
+}
+
+// long algebraic notation of the move used by UCI, e.g. e2e4, e1g1 or e7e8q
+func (m Move) UCI() string {
+	res := m.GetStart().String() + m.GetEnd().String()
+	if p := m.GetPromote(); p != NoPiece {
+		res += p.String() // type of the piece has black literal
+	}
+	return res
+}
+
+func parseUCIMoveText(s string) (Move, error) {
+	var res Move
+	if len(s) != 4 && len(s) != 5 {
+		return res, &ErrorMalformedMove{s}
+	}
+	start, er := MakePiecePosFromFEN(s[0:2])
+	if er != nil {
+		return res, &ErrorMalformedMove{s}
+	}
+	end, er := MakePiecePosFromFEN(s[2:4])
+	if er != nil {
+		return res, &ErrorMalformedMove{s}
+	}
+	res = res.SetStart(start).SetEnd(end)
+	if len(s) == 5 {
+		promote, er := MakePieceFromRune(rune(strings.ToLower(s[4:])[0]))
+		switch {
+		case er != nil:
+			return res, &ErrorMalformedMove{s}
+		case promote == Knight || promote == Bishop || promote == Rook || promote == Queen:
+			res = res.SetPromote(promote)
+		default:
+			return res, &ErrorMalformedMove{s}
+		}
+	}
+	return res, nil
+}
+
+// resolves UCI move to the legal move of the position with all flags set,
+// castling given as the king capturing its own rook (e1h1) is accepted too
+func ParseUCIMove(s string, board *Board, bs BoardState) (Move, error) {
+	move, er := parseUCIMoveText(s)
+	if er != nil {
+		return move, er
+	}
+	var buf [256]Move
+	legal := AppendLegalMoves(buf[:0], board, bs)
+	if res, found := findMove(legal, move.Compact()); found {
+		return res, nil
+	}
+
+	start := move.GetStart()
+	end := move.GetEnd()
+	king := board.GetPiece(start)
+	if king.GetType() == King && board.GetPiece(end) == Rook|(king&White) && start.GetRow() == end.GetRow() {
+		castle := move
+		if end.GetCol() > start.GetCol() {
+			castle = castle.SetEnd(MakePos(start.GetRow(), start.GetCol()+2))
+		} else {
+			castle = castle.SetEnd(MakePos(start.GetRow(), start.GetCol()-2))
+		}
+		if res, found := findMove(legal, castle.Compact()); found && res.IsCastle() {
+			return res, nil
+		}
+	}
+	return move, &ErrorIllegalMove{move}
+}
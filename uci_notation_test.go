@@ -0,0 +1,51 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestUCIMoveFormat(t *testing.T) {
+	assert_equal(makeTestMove("e2", "e4", NoPiece).UCI(), "e2e4", t)
+	assert_equal(makeTestMove("e7", "e8", Queen).UCI(), "e7e8q", t)
+	assert_equal(makeTestMove("a2", "b1", W_Knight).UCI(), "a2b1n", t)
+	assert_equal(makeTestMove("e1", "g1", NoPiece).String(), "e1g1", t)
+}
+
+func TestParseUCIMove(t *testing.T) {
+	board, bs := makeTestPosition("r3k2r/1P6/8/3pP3/8/8/4P3/R3K2R w KQkq d6 0 1", t)
+	cases := []struct {
+		s     string
+		flags Move
+	}{
+		{"e2e4", MoveFlagDoublePush},
+		{"e5d6", MoveFlagCapture | MoveFlagEnPassant},
+		{"e1g1", MoveFlagKingCastle},
+		{"e1c1", MoveFlagQueenCastle},
+		{"e1h1", MoveFlagKingCastle},
+		{"e1a1", MoveFlagQueenCastle},
+		{"b7a8q", MoveFlagCapture},
+		{"b7b8N", 0},
+	}
+	for _, c := range cases {
+		move, er := ParseUCIMove(c.s, &board, bs)
+		assert_er(er, t)
+		assert_equal(move.GetFlags(), c.flags, t)
+		if c.s != "e1h1" && c.s != "e1a1" && c.s != "b7b8N" {
+			assert_equal(move.UCI(), c.s, t)
+		}
+	}
+
+	for _, s := range []string{"", "e2", "e2e4e", "i2i4", "e2e9", "b7b8k", "b7b8p", "e2e4qq"} {
+		var malformed *ErrorMalformedMove
+		if _, er := ParseUCIMove(s, &board, bs); !errors.As(er, &malformed) {
+			t.Errorf("%q: expected malformed move error, got %v", s, er)
+		}
+	}
+	for _, s := range []string{"e2e5", "b7b8", "e5e6q", "a1a8q", "e8g8", "d1d2"} {
+		var illegal *ErrorIllegalMove
+		if _, er := ParseUCIMove(s, &board, bs); !errors.As(er, &illegal) {
+			t.Errorf("%q: expected illegal move error, got %v", s, er)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func runTestUCI(commands []string, t *testing.T) string {
+	var sb strings.Builder
+	e := MakeUCIEngine(&sb)
+	for _, command := range commands {
+		if e.Handle(command) {
+			break
+		}
+		e.waitSearch()
+	}
+	e.stopSearch()
+	return sb.String()
+}
+
+func assert_contains(s, expected string, t *testing.T) {
+	if !strings.Contains(s, expected) {
+		t.Errorf("%q not found in output:\n%s", expected, s)
+	}
+}
+
+func TestUCIHandshake(t *testing.T) {
+	out := runTestUCI([]string{"uci", "isready", "foo"}, t)
+	assert_contains(out, "id name enginsant\n", t)
+	assert_contains(out, "uciok\nreadyok\n", t)
+	assert_contains(out, "info string unknown command foo\n", t)
+}
+
+func TestUCIPosition(t *testing.T) {
+	var sb strings.Builder
+	e := MakeUCIEngine(&sb)
+	e.Handle("position startpos moves e2e4 e7e5 g1f3")
+	assert_equal(e.game.Position.String(), "rnbqkbnr/pppp1ppp/8/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R b KQkq - 1 2", t)
+	assert_equal(len(e.game.Moves()), 3, t)
+
+	e.Handle("position fen 4k3/8/8/8/8/8/8/R3K2R w KQ - 3 20 moves e1g1")
+	assert_equal(e.game.Position.String(), "4k3/8/8/8/8/8/8/R4RK1 b - - 4 20", t)
+
+	e.Handle("position startpos moves e2e5")
+	assert_contains(sb.String(), "info string illegal move", t)
+	assert_equal(e.game.Position.String(), "4k3/8/8/8/8/8/8/R4RK1 b - - 4 20", t)
+}
+
+func TestUCIGo(t *testing.T) {
+	out := runTestUCI([]string{"position fen 6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1", "go depth 1"}, t)
+	assert_contains(out, "score mate 1 ", t)
+	assert_contains(out, "pv a1a8\n", t)
+	assert_contains(out, "bestmove a1a8\n", t)
+
+	out = runTestUCI([]string{"position fen 7k/5Q2/6K1/8/8/8/8/8 b - - 0 1", "go wtime 1000 btime 1000"}, t)
+	assert_contains(out, "bestmove 0000\n", t)
+
+	out = runTestUCI([]string{"position fen 7k/8/8/8/8/8/R7/1R5K w - - 0 1", "go mate 2"}, t)
+	assert_contains(out, "score mate 2 ", t)
+	out = runTestUCI([]string{"position startpos", "go mate 1"}, t)
+	assert_contains(out, "info depth 1 ", t)
+	assert_equal(strings.Contains(out, "info depth 2 "), false, t)
+	assert_contains(out, "bestmove ", t)
+}
+
+func TestUCIStop(t *testing.T) {
+	var sb strings.Builder
+	e := MakeUCIEngine(&sb)
+	e.Handle("go infinite")
+	e.Handle("isready")
+	e.out_mu.Lock()
+	assert_equal(strings.Contains(sb.String(), "bestmove"), false, t)
+	e.out_mu.Unlock()
+	e.Handle("stop")
+	assert_contains(sb.String(), "bestmove", t)
+
+	sb.Reset()
+	e.Handle("go ponder movetime 100")
+	e.Handle("ponderhit")
+	e.waitSearch()
+	assert_contains(sb.String(), "bestmove", t)
+	assert_equal(e.Handle("quit"), true, t)
+}
@@ -0,0 +1,152 @@
+package main
+
+import "fmt"
+
+type ValidationIssueKind uint8
+
+const (
+	IssueKingCount         ValidationIssueKind = iota // side has no king or more than one
+	IssuePawnOnBackRank                               // pawn on the 1st or 8th rank
+	IssueTooManyPawns                                 // more than 8 pawns of one side
+	IssueTooManyPieces                                // pawns plus promoted pieces are more than 8
+	IssueCastleWithoutKing                            // castle right, but king is not on its home square
+	IssueCastleWithoutRook                            // castle right, but rook is not on its home square
+	IssueEnPassantSquare                              // en passant square is not behind a just moved pawn
+	IssueOpponentInCheck                              // side not to move is in check
+)
+
+var validationIssueNames = [...]string{
+	IssueKingCount:         "wrong number of kings",
+	IssuePawnOnBackRank:    "pawn on back rank",
+	IssueTooManyPawns:      "more than 8 pawns",
+	IssueTooManyPieces:     "too many promoted pieces",
+	IssueCastleWithoutKing: "castle right without king on its square",
+	IssueCastleWithoutRook: "castle right without rook on its square",
+	IssueEnPassantSquare:   "impossible en passant square",
+	IssueOpponentInCheck:   "side not to move is in check",
+}
+
+func (k ValidationIssueKind) String() string {
+	if int(k) < len(validationIssueNames) {
+		return validationIssueNames[k]
+	}
+	return "unknown issue"
+}
+
+// violation found by Validate, Pos is the related square if there is one
+type ValidationIssue struct {
+	Kind    ValidationIssueKind
+	IsWhite bool
+	Pos     Position
+	HasPos  bool
+}
+
+func (i ValidationIssue) Error() string {
+	color := "black"
+	if i.IsWhite {
+		color = "white"
+	}
+	if i.HasPos {
+		return fmt.Sprintf("%s: %s at %s", color, i.Kind, i.Pos)
+	}
+	return fmt.Sprintf("%s: %s", color, i.Kind)
+}
+
+func validatePieces(board *Board, is_white bool, issues []ValidationIssue) []ValidationIssue {
+	var counts [King + 1]int
+	for i, piece := range board {
+		if piece == NoPiece || piece.IsWhite() != is_white {
+			continue
+		}
+		counts[piece.GetType()]++
+		if piece.GetType() == Pawn {
+			if r := Position(i).GetRow(); r == 0 || r == BoardSize-1 {
+				issues = append(issues, ValidationIssue{IssuePawnOnBackRank, is_white, Position(i), true})
+			}
+		}
+	}
+	if counts[King] != 1 {
+		issues = append(issues, ValidationIssue{Kind: IssueKingCount, IsWhite: is_white})
+	}
+	if counts[Pawn] > 8 {
+		issues = append(issues, ValidationIssue{Kind: IssueTooManyPawns, IsWhite: is_white})
+	}
+	promoted := max(counts[Queen]-1, 0) + max(counts[Rook]-2, 0) + max(counts[Bishop]-2, 0) + max(counts[Knight]-2, 0)
+	// pawns beyond eight are already reported above
+	if min(counts[Pawn], 8)+promoted > 8 {
+		issues = append(issues, ValidationIssue{Kind: IssueTooManyPieces, IsWhite: is_white})
+	}
+	return issues
+}
+
+func validateCastle(board *Board, is_white bool, king_side bool, issues []ValidationIssue) []ValidationIssue {
+	var r int8 = 7
+	var color Piece = 0
+	if is_white {
+		r = 0
+		color = White
+	}
+	rook_pos := MakePos(r, 0)
+	if king_side {
+		rook_pos = MakePos(r, 7)
+	}
+	if king_pos := MakePos(r, 4); board.GetPiece(king_pos) != King|color {
+		issues = append(issues, ValidationIssue{IssueCastleWithoutKing, is_white, king_pos, true})
+	}
+	if board.GetPiece(rook_pos) != Rook|color {
+		issues = append(issues, ValidationIssue{IssueCastleWithoutRook, is_white, rook_pos, true})
+	}
+	return issues
+}
+
+func validateEnPassant(board *Board, bs BoardState, issues []ValidationIssue) []ValidationIssue {
+	if !bs.Get_IsEnPos() {
+		return issues
+	}
+	// en passant square belongs to the side which just moved
+	is_white := !bs.Get_Turn()
+	en_pos := bs.Get_EnPos()
+	var r, dir int8 = 5, -1
+	var pawn Piece = B_Pawn
+	if is_white {
+		r, dir = 2, 1
+		pawn = W_Pawn
+	}
+	c := en_pos.GetCol()
+	if en_pos.GetRow() != r ||
+		board.GetPiece(en_pos) != NoPiece ||
+		board.GetPiece(MakePos(r-dir, c)) != NoPiece ||
+		board.GetPiece(MakePos(r+dir, c)) != pawn {
+		issues = append(issues, ValidationIssue{IssueEnPassantSquare, is_white, en_pos, true})
+	}
+	return issues
+}
+
+// returns all found violations of chess rules in the position, empty result means the position is legal
+func Validate(board *Board, bs BoardState) []ValidationIssue {
+	var issues []ValidationIssue
+	for _, is_white := range [2]bool{true, false} {
+		issues = validatePieces(board, is_white, issues)
+	}
+
+	if bs.Get_K() {
+		issues = validateCastle(board, true, true, issues)
+	}
+	if bs.Get_Q() {
+		issues = validateCastle(board, true, false, issues)
+	}
+	if bs.Get_k() {
+		issues = validateCastle(board, false, true, issues)
+	}
+	if bs.Get_q() {
+		issues = validateCastle(board, false, false, issues)
+	}
+
+	issues = validateEnPassant(board, bs, issues)
+
+	waiting := !bs.Get_Turn()
+	if king_pos, found := FindKing(board, waiting); found && IsSquareAttacked(king_pos, board, !waiting) {
+		issues = append(issues, ValidationIssue{IssueOpponentInCheck, waiting, king_pos, true})
+	}
+	return issues
+}
@@ -0,0 +1,52 @@
+package main
+
+import "testing"
+
+func TestValidateLegal(t *testing.T) {
+	for _, fen := range []string{
+		initialFEN,
+		"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1",
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"4k3/8/8/8/8/8/PPPPPPP1/Q2QK3 w - - 0 1",
+	} {
+		gp, er := ParseFEN(fen)
+		assert_er(er, t)
+		if issues := Validate(&gp.Board, gp.State); len(issues) != 0 {
+			t.Errorf("%s: unexpected issues %v", fen, issues)
+		}
+	}
+}
+
+func TestValidateIssues(t *testing.T) {
+	cases := []struct {
+		fen    string
+		issues []ValidationIssueKind
+	}{
+		{"4k3/8/8/8/8/8/8/K3K3 w - - 0 1", []ValidationIssueKind{IssueKingCount}},
+		{"8/8/8/8/8/8/8/4K3 w - - 0 1", []ValidationIssueKind{IssueKingCount}},
+		{"P3k3/8/8/8/8/8/8/4K2p w - - 0 1", []ValidationIssueKind{IssuePawnOnBackRank, IssuePawnOnBackRank}},
+		{"4k3/8/8/8/8/P7/PPPPPPPP/4K3 w - - 0 1", []ValidationIssueKind{IssueTooManyPawns}},
+		{"4k3/8/8/8/8/P7/PPPPPPPP/Q2QK3 w - - 0 1", []ValidationIssueKind{IssueTooManyPawns, IssueTooManyPieces}},
+		{"4k3/8/8/8/8/Q7/PPPPPPPP/Q3K3 w - - 0 1", []ValidationIssueKind{IssueTooManyPieces}},
+		{"4k2r/8/8/8/8/8/8/4K2R w Kq - 0 1", []ValidationIssueKind{IssueCastleWithoutRook}},
+		{"r5k1/8/8/8/8/8/8/R3K2R w KQq - 0 1", []ValidationIssueKind{IssueCastleWithoutKing}},
+		{"4k3/8/8/8/8/8/4P3/4K3 b - e3 0 1", []ValidationIssueKind{IssueEnPassantSquare}},
+		{"4k3/8/8/8/4P3/8/8/4K3 w - e3 0 1", []ValidationIssueKind{IssueEnPassantSquare}},
+		{"4k3/8/8/8/8/8/8/4K2r b - - 0 1", []ValidationIssueKind{IssueOpponentInCheck}},
+	}
+	for _, c := range cases {
+		gp, er := ParseFEN(c.fen)
+		assert_er(er, t)
+		issues := Validate(&gp.Board, gp.State)
+		if len(issues) != len(c.issues) {
+			t.Errorf("%s: issues %v, expected %v", c.fen, issues, c.issues)
+			continue
+		}
+		for i, issue := range issues {
+			if issue.Kind != c.issues[i] {
+				t.Errorf("%s: issues %v, expected %v", c.fen, issues, c.issues)
+				break
+			}
+		}
+	}
+}
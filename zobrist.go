@@ -0,0 +1,91 @@
+package main
+
+import "fmt"
+
+// enables check of the incremental hash against the full recompute after every move of GamePosition
+const zobristDebug = false
+
+var (
+	zobristPieces    [King | White + 1][BoardSize * BoardSize]uint64 // keys of NoPiece are zero
+	zobristCastle    [4]uint64                                       // K, Q, k, q
+	zobristEnPassant [BoardSize]uint64                               // by column
+	zobristWhiteTurn uint64
+)
+
+// fixed seed keeps hashes the same between runs
+const zobristSeed uint64 = 0x2545f4914f6cdd1d
+
+// splitmix64 generator
+func nextZobristKey(state *uint64) uint64 {
+	*state += 0x9e3779b97f4a7c15
+	z := *state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+func init() {
+	state := zobristSeed
+	for _, pl := range pieceLieterals {
+		if pl.piece == NoPiece {
+			continue
+		}
+		for i := range zobristPieces[pl.piece] {
+			zobristPieces[pl.piece][i] = nextZobristKey(&state)
+		}
+	}
+	for i := range zobristCastle {
+		zobristCastle[i] = nextZobristKey(&state)
+	}
+	for i := range zobristEnPassant {
+		zobristEnPassant[i] = nextZobristKey(&state)
+	}
+	zobristWhiteTurn = nextZobristKey(&state)
+}
+
+func zobristPiece(p Piece, pos Position) uint64 {
+	return zobristPieces[p][pos]
+}
+
+// full recompute of the hash of pieces on the board
+func (board *Board) Hash() uint64 {
+	var res uint64
+	for i, piece := range board {
+		res ^= zobristPiece(piece, Position(i))
+	}
+	return res
+}
+
+// hash of turn, castle rights and en passant column, half-move clock is not included
+func (bs BoardState) Hash() uint64 {
+	var res uint64
+	if bs.Get_Turn() {
+		res ^= zobristWhiteTurn
+	}
+	if bs.Get_K() {
+		res ^= zobristCastle[0]
+	}
+	if bs.Get_Q() {
+		res ^= zobristCastle[1]
+	}
+	if bs.Get_k() {
+		res ^= zobristCastle[2]
+	}
+	if bs.Get_q() {
+		res ^= zobristCastle[3]
+	}
+	if bs.Get_IsEnPos() {
+		res ^= zobristEnPassant[bs.Get_EnPos().GetCol()]
+	}
+	return res
+}
+
+func PositionHash(board *Board, bs BoardState) uint64 {
+	return board.Hash() ^ bs.Hash()
+}
+
+func checkZobristHash(gp *GamePosition) {
+	if full := PositionHash(&gp.Board, gp.State); full != gp.hash {
+		panic(fmt.Sprintf("incremental hash %x differs from full hash %x in %s", gp.hash, full, gp.String()))
+	}
+}
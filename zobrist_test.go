@@ -0,0 +1,47 @@
+package main
+
+import "testing"
+
+func checkHashWalk(gp *GamePosition, depth int, t *testing.T) {
+	if full := PositionHash(&gp.Board, gp.State); full != gp.Hash() {
+		t.Fatalf("%s: incremental hash %x, full hash %x", gp.String(), gp.Hash(), full)
+	}
+	if depth == 0 {
+		return
+	}
+	for _, move := range GenerateLegalMoves(&gp.Board, gp.State) {
+		undo := gp.MakeMove(move)
+		checkHashWalk(gp, depth-1, t)
+		gp.UnmakeMove(move, undo)
+	}
+}
+
+func TestZobristIncremental(t *testing.T) {
+	for _, fen := range []string{perftInitial, perftKiwipete, perftPos3, perftPos4, perftPos5} {
+		gp, er := ParseFEN(fen)
+		assert_er(er, t)
+		hash := gp.Hash()
+		checkHashWalk(&gp, 3, t)
+		assert_equal(gp.Hash(), hash, t)
+	}
+}
+
+func TestZobristTransposition(t *testing.T) {
+	a := MakeInitialGamePosition()
+	b := MakeInitialGamePosition()
+	a.MakeMove(makeTestMove("g1", "f3", NoPiece))
+	a.MakeMove(makeTestMove("g8", "f6", NoPiece))
+	a.MakeMove(makeTestMove("b1", "c3", NoPiece))
+	b.MakeMove(makeTestMove("b1", "c3", NoPiece))
+	b.MakeMove(makeTestMove("g8", "f6", NoPiece))
+	b.MakeMove(makeTestMove("g1", "f3", NoPiece))
+	assert_equal(a.Hash(), b.Hash(), t)
+
+	// same pieces, different state
+	c, _ := ParseFEN("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR b KQkq - 0 1")
+	d, _ := ParseFEN("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w Kkq - 0 1")
+	initial := MakeInitialGamePosition()
+	if c.Hash() == initial.Hash() || d.Hash() == initial.Hash() || c.Hash() == d.Hash() {
+		t.Error("hash does not depend on the board state")
+	}
+}